@@ -5,18 +5,29 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	_ "github.com/HellEaglee/Golang-Chat/docs"
 	jwt "github.com/HellEaglee/Golang-Chat/internal/adapter/auth/JWT"
 	"github.com/HellEaglee/Golang-Chat/internal/adapter/config"
+	"github.com/HellEaglee/Golang-Chat/internal/adapter/eventbus"
 	httphandler "github.com/HellEaglee/Golang-Chat/internal/adapter/handler/http"
+	"github.com/HellEaglee/Golang-Chat/internal/adapter/imaging"
 	"github.com/HellEaglee/Golang-Chat/internal/adapter/logger"
+	"github.com/HellEaglee/Golang-Chat/internal/adapter/realtime"
+	"github.com/HellEaglee/Golang-Chat/internal/adapter/storage/local"
 	"github.com/HellEaglee/Golang-Chat/internal/adapter/storage/postgres"
 	"github.com/HellEaglee/Golang-Chat/internal/adapter/storage/postgres/repository"
+	"github.com/HellEaglee/Golang-Chat/internal/adapter/unfurl"
+	"github.com/HellEaglee/Golang-Chat/internal/core/port"
 	"github.com/HellEaglee/Golang-Chat/internal/core/service"
 )
 
+// defaultMaxAttachmentSize applies when STORAGE_MAX_ATTACHMENT_SIZE is not set
+const defaultMaxAttachmentSize = 10 << 20
+
 func init() {
 	time.Local = time.UTC
 }
@@ -58,6 +69,24 @@ func main() {
 
 	// DI
 	csrf := service.NewCSRFService()
+	hub := realtime.NewHub()
+
+	var publisher port.EventPublisher
+	var signaler port.SignalPublisher
+	var subscriber port.EventSubscriber
+	switch config.Event.Driver {
+	case "memory":
+		bus := eventbus.NewMemoryBus()
+		publisher, signaler, subscriber = bus, bus, bus
+	default:
+		bus := eventbus.NewPostgresBus(db)
+		go func() {
+			if err := bus.Listen(ctx); err != nil {
+				slog.Error("Error listening for events", "error", err)
+			}
+		}()
+		publisher, signaler, subscriber = bus, bus, bus
+	}
 
 	tokenRepo := repository.NewTokenRepository(db)
 	token, err := jwt.New(config.Token, tokenRepo)
@@ -67,9 +96,106 @@ func main() {
 	}
 
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo)
+	chatRepo := repository.NewChatRepository(db)
+	messageRepo := repository.NewMessageRepository(db)
+	presenceRepo := repository.NewPresenceRepository(db)
+	presenceService := service.NewPresenceService(presenceRepo, userRepo, chatRepo, publisher)
+	go func() {
+		if err := presenceService.Run(ctx); err != nil {
+			slog.Error("Error keeping presence connections alive", "error", err)
+		}
+	}()
+	userService := service.NewUserService(userRepo, presenceService)
 	userHandler := httphandler.NewUserHandler(userService)
 
+	chatService := service.NewChatService(chatRepo, userRepo, messageRepo, publisher)
+	chatHandler := httphandler.NewChatHandler(chatService)
+	participantHandler := httphandler.NewParticipantHandler(chatService)
+
+	var editWindow time.Duration
+	if config.Message.EditWindow != "" {
+		editWindow, err = time.ParseDuration(config.Message.EditWindow)
+		if err != nil {
+			slog.Error("Error parsing message edit window", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	var maxPins int
+	if config.Message.MaxPins != "" {
+		maxPins, err = strconv.Atoi(config.Message.MaxPins)
+		if err != nil || maxPins < 0 {
+			slog.Error("Error parsing message pin limit", "value", config.Message.MaxPins)
+			os.Exit(1)
+		}
+	}
+
+	blobs, err := local.New(config.Storage)
+	if err != nil {
+		slog.Error("Error initializing blob storage", "error", err)
+		os.Exit(1)
+	}
+
+	maxAttachmentSize := int64(defaultMaxAttachmentSize)
+	if config.Storage.MaxAttachmentSize != "" {
+		maxAttachmentSize, err = strconv.ParseInt(config.Storage.MaxAttachmentSize, 10, 64)
+		if err != nil || maxAttachmentSize <= 0 {
+			slog.Error("Error parsing attachment size limit", "value", config.Storage.MaxAttachmentSize)
+			os.Exit(1)
+		}
+	}
+
+	var allowedAttachmentTypes []string
+	for _, contentType := range strings.Split(config.Storage.AllowedAttachmentTypes, ",") {
+		if contentType = strings.TrimSpace(contentType); contentType != "" {
+			allowedAttachmentTypes = append(allowedAttachmentTypes, contentType)
+		}
+	}
+
+	attachmentRepo := repository.NewAttachmentRepository(db)
+	attachmentService := service.NewAttachmentService(attachmentRepo, blobs, imaging.New(), publisher, maxAttachmentSize, allowedAttachmentTypes)
+	go func() {
+		if err := attachmentService.RunThumbnailWorker(ctx); err != nil {
+			slog.Error("Error generating thumbnails", "error", err)
+		}
+	}()
+	attachmentHandler := httphandler.NewAttachmentHandler(attachmentService, chatService)
+
+	linkPreviewRepo := repository.NewLinkPreviewRepository(db)
+	linkPreviewService := service.NewLinkPreviewService(linkPreviewRepo, unfurl.New(), publisher)
+	go func() {
+		if err := linkPreviewService.Run(ctx); err != nil {
+			slog.Error("Error fetching link previews", "error", err)
+		}
+	}()
+
+	messageService := service.NewMessageService(messageRepo, chatRepo, presenceService, linkPreviewService, publisher, editWindow, maxPins)
+	messageHandler := httphandler.NewMessageHandler(messageService, chatService)
+
+	expiryService := service.NewExpiryService(messageRepo, blobs, publisher)
+	go func() {
+		if err := expiryService.Run(ctx); err != nil {
+			slog.Error("Error deleting expired messages", "error", err)
+		}
+	}()
+
+	scheduledMessageRepo := repository.NewScheduledMessageRepository(db)
+	scheduledMessageService := service.NewScheduledMessageService(scheduledMessageRepo, messageService, chatRepo)
+	go func() {
+		if err := scheduledMessageService.RunDispatcher(ctx); err != nil {
+			slog.Error("Error dispatching scheduled messages", "error", err)
+		}
+	}()
+	scheduledMessageHandler := httphandler.NewScheduledMessageHandler(scheduledMessageService, chatService)
+
+	typingService := service.NewTypingService(chatRepo, signaler)
+	realtimeHandler := httphandler.NewRealtimeHandler(config.HTTP, hub, chatService, messageService, typingService, presenceService, subscriber)
+	go func() {
+		if err := realtimeHandler.Run(ctx); err != nil {
+			slog.Error("Error forwarding realtime events", "error", err)
+		}
+	}()
+
 	authService := service.NewAuthService(userRepo, token)
 	authHandler := httphandler.NewAuthHandler(config.Token, authService, csrf)
 
@@ -80,6 +206,12 @@ func main() {
 		csrf,
 		*authHandler,
 		*userHandler,
+		*chatHandler,
+		*messageHandler,
+		*participantHandler,
+		*realtimeHandler,
+		*attachmentHandler,
+		*scheduledMessageHandler,
 	)
 	if err != nil {
 		slog.Error("Error initializing router", "error", err)
@@ -8,10 +8,13 @@ import (
 
 type (
 	Container struct {
-		App   *App
-		Token *Token
-		DB    *DB
-		HTTP  *HTTP
+		App     *App
+		Token   *Token
+		DB      *DB
+		HTTP    *HTTP
+		Event   *Event
+		Message *Message
+		Storage *Storage
 	}
 	App struct {
 		Name string
@@ -38,6 +41,18 @@ type (
 		Port           string
 		AllowedOrigins string
 	}
+	Event struct {
+		Driver string
+	}
+	Message struct {
+		EditWindow string
+		MaxPins    string
+	}
+	Storage struct {
+		Path                   string
+		MaxAttachmentSize      string
+		AllowedAttachmentTypes string
+	}
 )
 
 func New() (*Container, error) {
@@ -77,10 +92,28 @@ func New() (*Container, error) {
 		AllowedOrigins: os.Getenv("HTTP_ALLOWED_ORIGINS"),
 	}
 
+	event := &Event{
+		Driver: os.Getenv("EVENT_DRIVER"),
+	}
+
+	message := &Message{
+		EditWindow: os.Getenv("MESSAGE_EDIT_WINDOW"),
+		MaxPins:    os.Getenv("MESSAGE_MAX_PINS"),
+	}
+
+	storage := &Storage{
+		Path:                   os.Getenv("STORAGE_PATH"),
+		MaxAttachmentSize:      os.Getenv("STORAGE_MAX_ATTACHMENT_SIZE"),
+		AllowedAttachmentTypes: os.Getenv("STORAGE_ALLOWED_ATTACHMENT_TYPES"),
+	}
+
 	return &Container{
 		app,
 		token,
 		db,
 		http,
+		event,
+		message,
+		storage,
 	}, nil
 }
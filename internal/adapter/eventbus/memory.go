@@ -0,0 +1,84 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+)
+
+// subscriptionBufferSize is how many events a subscriber may lag behind before publishers start waiting on it
+const subscriptionBufferSize = 256
+
+type subscription struct {
+	events chan domain.Event
+	done   <-chan struct{}
+}
+
+// MemoryBus fans events out to the subscribers of the current process
+type MemoryBus struct {
+	mu            sync.RWMutex
+	subscriptions map[*subscription]struct{}
+	// lastID is the id of the latest published event; it starts from the boot time so ids are not reused after a restart
+	lastID atomic.Uint64
+}
+
+func NewMemoryBus() *MemoryBus {
+	bus := &MemoryBus{subscriptions: make(map[*subscription]struct{})}
+	bus.lastID.Store(uint64(time.Now().UnixMicro()))
+	return bus
+}
+
+// Publish numbers the event and hands it to every subscriber
+func (b *MemoryBus) Publish(ctx context.Context, event *domain.Event) error {
+	numbered := *event
+	numbered.ID = b.lastID.Add(1)
+	return b.deliver(ctx, &numbered)
+}
+
+// PublishSignal hands the event to every subscriber without an id
+func (b *MemoryBus) PublishSignal(ctx context.Context, event *domain.Event) error {
+	signal := *event
+	signal.ID = 0
+	return b.deliver(ctx, &signal)
+}
+
+// deliver hands the event to every subscriber as it is, keeping the id it was given
+func (b *MemoryBus) deliver(ctx context.Context, event *domain.Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subscriptions {
+		select {
+		case sub.events <- *event:
+		case <-sub.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBus) Subscribe(ctx context.Context) (<-chan domain.Event, error) {
+	sub := &subscription{
+		events: make(chan domain.Event, subscriptionBufferSize),
+		done:   ctx.Done(),
+	}
+
+	b.mu.Lock()
+	b.subscriptions[sub] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		// publishers hold the read lock while sending, so none of them can be blocked on the channel once we own the lock
+		b.mu.Lock()
+		delete(b.subscriptions, sub)
+		b.mu.Unlock()
+		close(sub.events)
+	}()
+
+	return sub.events, nil
+}
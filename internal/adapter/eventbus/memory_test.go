@@ -0,0 +1,58 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+)
+
+func TestMemoryBusNumbersEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus := NewMemoryBus()
+	events, err := bus.Subscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var previous uint64
+	for i := 0; i < 3; i++ {
+		event := &domain.Event{Type: domain.EventMessageCreated}
+		if err := bus.Publish(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+		if event.ID != 0 {
+			t.Errorf("the event of the publisher was numbered")
+		}
+
+		received := <-events
+		if received.ID <= previous {
+			t.Errorf("event id %d does not follow %d", received.ID, previous)
+		}
+		previous = received.ID
+	}
+}
+
+func TestMemoryBusSignalsTakeNoID(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus := NewMemoryBus()
+	events, err := bus.Subscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bus.Publish(ctx, &domain.Event{Type: domain.EventMessageCreated})
+	first := <-events
+	bus.PublishSignal(ctx, &domain.Event{ID: 99, Type: domain.EventTypingStarted})
+	if signal := <-events; signal.ID != 0 {
+		t.Errorf("signal came with the id %d", signal.ID)
+	}
+	bus.Publish(ctx, &domain.Event{Type: domain.EventMessageCreated})
+	if second := <-events; second.ID != first.ID+1 {
+		t.Errorf("event id %d after a signal, want %d", second.ID, first.ID+1)
+	}
+}
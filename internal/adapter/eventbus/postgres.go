@@ -0,0 +1,193 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HellEaglee/Golang-Chat/internal/adapter/storage/postgres"
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+const (
+	// notifyChannel is the LISTEN/NOTIFY channel shared by every API instance
+	notifyChannel = "chat_events"
+	// maxNotifyPayload keeps inline payloads below the 8000 byte NOTIFY limit, with room for the event id in front
+	maxNotifyPayload = 7900
+	// payloadRefPrefix marks a notification that only carries the id of a row in event_payloads
+	payloadRefPrefix = "#"
+	// eventIDSeparator follows the event id that starts the notification of every event
+	eventIDSeparator = ":"
+	// signalPrefix starts the notification of a signal, which has no id
+	signalPrefix = "!"
+	// payloadRetention is how long oversized payloads are kept for listeners to fetch
+	payloadRetention  = "5 minutes"
+	reconnectInterval = 3 * time.Second
+)
+
+// PostgresBus publishes events through Postgres NOTIFY so every instance listening on the channel receives them
+type PostgresBus struct {
+	db    *postgres.DB
+	local *MemoryBus
+}
+
+func NewPostgresBus(db *postgres.DB) *PostgresBus {
+	return &PostgresBus{db: db, local: NewMemoryBus()}
+}
+
+// Publish sends the event to all instances, this one included; local subscribers receive it back through Listen.
+// The event is numbered from the event_ids sequence as part of the notification, so every instance sees the same id.
+func (b *PostgresBus) Publish(ctx context.Context, event *domain.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	notification := string(payload)
+	if len(payload) > maxNotifyPayload {
+		var id int64
+		err = b.db.WithContext(ctx).
+			Raw("INSERT INTO event_payloads (payload) VALUES (?) RETURNING id", notification).
+			Scan(&id).Error
+		if err != nil {
+			return err
+		}
+		notification = payloadRefPrefix + strconv.FormatInt(id, 10)
+
+		err = b.db.WithContext(ctx).
+			Exec("DELETE FROM event_payloads WHERE created_at < NOW() - INTERVAL '" + payloadRetention + "'").Error
+		if err != nil {
+			slog.Warn("Error pruning event payloads", "error", err)
+		}
+	}
+
+	query := "SELECT pg_notify(?, nextval('event_ids')::text || '" + eventIDSeparator + "' || ?::text)"
+	return b.db.WithContext(ctx).Exec(query, notifyChannel, notification).Error
+}
+
+// PublishSignal sends the event to all instances without numbering it or storing anything. NOTIFY is the only channel
+// the instances share, so a signal still costs a round trip to Postgres, but it takes no id from event_ids, writes no
+// row and cannot be replayed. A signal too large for a notification is refused rather than stored in event_payloads.
+func (b *PostgresBus) PublishSignal(ctx context.Context, event *domain.Event) error {
+	signal := *event
+	signal.ID = 0
+	payload, err := json.Marshal(&signal)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxNotifyPayload {
+		return fmt.Errorf("signal of %d bytes does not fit in a notification", len(payload))
+	}
+
+	return b.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?::text)", notifyChannel, signalPrefix+string(payload)).Error
+}
+
+func (b *PostgresBus) Subscribe(ctx context.Context) (<-chan domain.Event, error) {
+	return b.local.Subscribe(ctx)
+}
+
+// Listen holds a dedicated connection on the notify channel and hands received events to local subscribers
+// until ctx is done, reconnecting whenever the connection drops
+func (b *PostgresBus) Listen(ctx context.Context) error {
+	for {
+		err := b.listen(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		slog.Error("Event listener disconnected", "error", err)
+
+		select {
+		case <-time.After(reconnectInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (b *PostgresBus) listen(ctx context.Context) error {
+	sqlDB, err := b.db.DB.DB()
+	if err != nil {
+		return err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		stdConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("unexpected driver connection %T", driverConn)
+		}
+		pgConn := stdConn.Conn()
+
+		if _, err := pgConn.Exec(ctx, "LISTEN "+notifyChannel); err != nil {
+			return err
+		}
+
+		for {
+			notification, err := pgConn.WaitForNotification(ctx)
+			if err != nil {
+				return err
+			}
+
+			event, err := b.decode(ctx, notification.Payload)
+			if err != nil {
+				slog.Error("Error decoding event", "error", err)
+				continue
+			}
+
+			if err := b.local.deliver(ctx, event); err != nil {
+				return err
+			}
+		}
+	})
+}
+
+func (b *PostgresBus) decode(ctx context.Context, notification string) (*domain.Event, error) {
+	id, payload, err := parseNotification(notification)
+	if err != nil {
+		return nil, err
+	}
+
+	if ref, ok := strings.CutPrefix(payload, payloadRefPrefix); ok && id != 0 {
+		err := b.db.WithContext(ctx).
+			Raw("SELECT payload FROM event_payloads WHERE id = ?", ref).
+			Scan(&payload).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var event domain.Event
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		return nil, err
+	}
+	event.ID = id
+	return &event, nil
+}
+
+// parseNotification splits a notification into the id of the event and its payload; signals come with the id 0
+func parseNotification(notification string) (uint64, string, error) {
+	if payload, ok := strings.CutPrefix(notification, signalPrefix); ok {
+		return 0, payload, nil
+	}
+
+	rawID, payload, ok := strings.Cut(notification, eventIDSeparator)
+	if !ok {
+		return 0, "", errors.New("notification without an event id")
+	}
+	id, err := strconv.ParseUint(rawID, 10, 64)
+	if err != nil || id == 0 {
+		return 0, "", fmt.Errorf("invalid event id %q", rawID)
+	}
+	return id, payload, nil
+}
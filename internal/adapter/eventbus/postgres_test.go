@@ -0,0 +1,36 @@
+package eventbus
+
+import "testing"
+
+func TestParseNotification(t *testing.T) {
+	tests := []struct {
+		notification string
+		wantID       uint64
+		wantPayload  string
+		wantErr      bool
+	}{
+		{notification: `42:{"Type":"message.created"}`, wantID: 42, wantPayload: `{"Type":"message.created"}`},
+		{notification: "7:#1234", wantID: 7, wantPayload: "#1234"},
+		// only the first separator ends the id, the payload may hold more of them
+		{notification: `9:{"Text":"a:b"}`, wantID: 9, wantPayload: `{"Text":"a:b"}`},
+		// signals carry no id and are never stored aside
+		{notification: `!{"Type":"typing.started"}`, wantPayload: `{"Type":"typing.started"}`},
+		{notification: "!#1234", wantPayload: "#1234"},
+		{notification: `{"Type":"message.created"}`, wantErr: true},
+		{notification: ":payload", wantErr: true},
+		{notification: "0:payload", wantErr: true},
+		{notification: "-1:payload", wantErr: true},
+		{notification: "abc:payload", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		id, payload, err := parseNotification(tt.notification)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseNotification(%q) error = %v, want error %v", tt.notification, err, tt.wantErr)
+			continue
+		}
+		if id != tt.wantID || payload != tt.wantPayload {
+			t.Errorf("parseNotification(%q) = %d, %q, want %d, %q", tt.notification, id, payload, tt.wantID, tt.wantPayload)
+		}
+	}
+}
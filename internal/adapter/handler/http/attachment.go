@@ -0,0 +1,228 @@
+package httphandler
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/HellEaglee/Golang-Chat/internal/core/port"
+	"github.com/HellEaglee/Golang-Chat/internal/core/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// attachmentFormField is the multipart field carrying the uploaded file
+const attachmentFormField = "file"
+
+type AttachmentHandler struct {
+	service     port.AttachmentService
+	chatService port.ChatService
+}
+
+func NewAttachmentHandler(service port.AttachmentService, chatService port.ChatService) *AttachmentHandler {
+	return &AttachmentHandler{service: service, chatService: chatService}
+}
+
+// UploadAttachment godoc
+//
+//	@Summary		Upload an attachment
+//	@Description	Upload a file to a chat the current user participates in. The upload stays unsent until its id is
+//	@Description	passed in attachment_ids when sending a message. Size and type limits are configured on the server.
+//	@Tags			Attachments
+//	@Accept			mpfd
+//	@Produce		json
+//	@Param			id		path		string				true	"Chat ID (UUID)"
+//	@Param			file	formData	file				true	"File to upload"
+//	@Success		200		{object}	attachmentResponse	"Attachment uploaded"
+//	@Failure		400		{object}	errorResponse		"Validation error"
+//	@Failure		401		{object}	errorResponse		"Unauthorized error"
+//	@Failure		403		{object}	errorResponse		"Forbidden error"
+//	@Failure		413		{object}	errorResponse		"File too large error"
+//	@Failure		415		{object}	errorResponse		"Unsupported file type error"
+//	@Failure		500		{object}	errorResponse		"Internal server error"
+//	@Router			/chats/{id}/attachments [post]
+func (handler *AttachmentHandler) UploadAttachment(ctx *gin.Context) {
+	var uri chatMessagesRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	if err := checkParticipant(ctx, handler.chatService, uri.ChatID, userID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	// the file is streamed from the request rather than buffered by the multipart parser
+	reader, err := ctx.Request.MultipartReader()
+	if err != nil {
+		validationError(ctx, err)
+		return
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			validationError(ctx, errors.New("the file field is required"))
+			return
+		}
+		if err != nil {
+			validationError(ctx, err)
+			return
+		}
+		if part.FormName() != attachmentFormField || part.FileName() == "" {
+			part.Close()
+			continue
+		}
+
+		attachment := &domain.Attachment{
+			ChatID:   uuid.MustParse(uri.ChatID),
+			UserID:   userID,
+			FileName: part.FileName(),
+		}
+		createdAttachment, err := handler.service.UploadAttachment(ctx.Request.Context(), attachment, part)
+		part.Close()
+		if err != nil {
+			handleError(ctx, err)
+			return
+		}
+
+		rsp := newAttachmentResponse(createdAttachment)
+		handleSuccess(ctx, rsp)
+		return
+	}
+}
+
+type attachmentRequest struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// DownloadAttachment godoc
+//
+//	@Summary		Download an attachment
+//	@Description	Download the content of an attachment of a chat the current user participates in.
+//	@Description	Unsent uploads can only be downloaded by their uploader.
+//	@Tags			Attachments
+//	@Produce		octet-stream
+//	@Param			id	path		string			true	"Attachment ID (UUID)"
+//	@Success		200	{file}		file			"Attachment content"
+//	@Failure		400	{object}	errorResponse	"Validation error"
+//	@Failure		401	{object}	errorResponse	"Unauthorized error"
+//	@Failure		403	{object}	errorResponse	"Forbidden error"
+//	@Failure		404	{object}	errorResponse	"Data not found error"
+//	@Failure		500	{object}	errorResponse	"Internal server error"
+//	@Router			/attachments/{id} [get]
+func (handler *AttachmentHandler) DownloadAttachment(ctx *gin.Context) {
+	var uri attachmentRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	attachment, err := handler.getAttachment(ctx, uri.ID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	content, err := handler.service.OpenAttachment(ctx.Request.Context(), attachment)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	defer content.Close()
+
+	serveContent(ctx, content, attachment.Size, attachment.ContentType, attachment.FileName)
+}
+
+type thumbnailRequest struct {
+	ID   string `uri:"id" binding:"required,uuid"`
+	Size string `uri:"size" binding:"required,oneof=small medium"`
+}
+
+// DownloadThumbnail godoc
+//
+//	@Summary		Download an image thumbnail
+//	@Description	Download a thumbnail of an image attachment, once its thumbnail_status is ready
+//	@Tags			Attachments
+//	@Produce		image/jpeg,image/png
+//	@Param			id		path		string			true	"Attachment ID (UUID)"
+//	@Param			size	path		string			true	"Thumbnail size"	Enums(small, medium)
+//	@Success		200		{file}		file			"Thumbnail content"
+//	@Failure		400		{object}	errorResponse	"Validation error"
+//	@Failure		401		{object}	errorResponse	"Unauthorized error"
+//	@Failure		403		{object}	errorResponse	"Forbidden error"
+//	@Failure		404		{object}	errorResponse	"Data not found error"
+//	@Failure		500		{object}	errorResponse	"Internal server error"
+//	@Router			/attachments/{id}/thumbnails/{size} [get]
+func (handler *AttachmentHandler) DownloadThumbnail(ctx *gin.Context) {
+	var uri thumbnailRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	attachment, err := handler.getAttachment(ctx, uri.ID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	index := slices.IndexFunc(attachment.Thumbnails, func(thumbnail domain.AttachmentThumbnail) bool {
+		return thumbnail.Size == uri.Size
+	})
+	if index < 0 {
+		handleError(ctx, util.ErrDataNotFound)
+		return
+	}
+	thumbnail := &attachment.Thumbnails[index]
+
+	content, err := handler.service.OpenThumbnail(ctx.Request.Context(), thumbnail)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	defer content.Close()
+
+	// thumbnails are small, their length is left to chunked encoding
+	serveContent(ctx, content, -1, thumbnail.ContentType, uri.Size+"-"+attachment.FileName)
+}
+
+// getAttachment is a helper function to load an attachment the current user is allowed to download
+func (handler *AttachmentHandler) getAttachment(ctx *gin.Context, id string) (*domain.Attachment, error) {
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		return nil, util.ErrUnauthorized
+	}
+
+	attachment, err := handler.service.GetAttachment(ctx.Request.Context(), id)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.MessageID == nil && attachment.UserID != userID {
+		return nil, util.ErrDataNotFound
+	}
+
+	if err := checkParticipant(ctx, handler.chatService, attachment.ChatID.String(), userID); err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// serveContent is a helper function to stream stored files; they are served as downloads
+// so user content never renders in the API origin
+func serveContent(ctx *gin.Context, content io.Reader, size int64, contentType, fileName string) {
+	ctx.DataFromReader(http.StatusOK, size, contentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": fileName}),
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, max-age=86400",
+	})
+}
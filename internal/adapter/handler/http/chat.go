@@ -0,0 +1,423 @@
+package httphandler
+
+import (
+	"time"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/HellEaglee/Golang-Chat/internal/core/port"
+	"github.com/HellEaglee/Golang-Chat/internal/core/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ChatHandler struct {
+	service port.ChatService
+}
+
+func NewChatHandler(service port.ChatService) *ChatHandler {
+	return &ChatHandler{service: service}
+}
+
+type createChatRequest struct {
+	Name           *string  `json:"name" binding:"omitempty,max=100" example:"Team chat"`
+	IsGroup        bool     `json:"is_group" example:"true"`
+	ParticipantIDs []string `json:"participant_ids" binding:"omitempty,dive,uuid" example:"3342a227-1f2d-4422-a718-435c6a115f62"`
+}
+
+// CreateChat godoc
+//
+//	@Summary		Create a new chat
+//	@Description	Create a new group chat with the current user as its admin and the given users as members.
+//	@Description	Non-group chats take exactly one other participant and return the existing direct chat if there is one.
+//	@Tags			Chats
+//	@Accept			json
+//	@Produce		json
+//	@Param			chat	body		createChatRequest	true	"Create chat request"
+//	@Success		200		{object}	chatResponse		"Chat created"
+//	@Failure		400		{object}	errorResponse		"Validation error"
+//	@Failure		401		{object}	errorResponse		"Unauthorized error"
+//	@Failure		500		{object}	errorResponse		"Internal server error"
+//	@Router			/chats [post]
+func (handler *ChatHandler) CreateChat(ctx *gin.Context) {
+	var req createChatRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	participants := []domain.ChatParticipant{{UserID: userID, Role: domain.ChatRoleAdmin}}
+	seen := map[uuid.UUID]bool{userID: true}
+	for _, id := range req.ParticipantIDs {
+		participantID := uuid.MustParse(id)
+		if seen[participantID] {
+			continue
+		}
+		seen[participantID] = true
+		participants = append(participants, domain.ChatParticipant{UserID: participantID, Role: domain.ChatRoleMember})
+	}
+
+	chat := &domain.Chat{
+		ID:           uuid.New(),
+		Name:         req.Name,
+		IsGroup:      req.IsGroup,
+		Participants: participants,
+	}
+
+	createdChat, err := handler.service.CreateChat(ctx.Request.Context(), chat)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	rsp := newChatResponse(createdChat)
+	handleSuccess(ctx, rsp)
+}
+
+type directChatRequest struct {
+	UserID string `uri:"userID" binding:"required,uuid"`
+}
+
+// GetOrCreateDirectChat godoc
+//
+//	@Summary		Open a direct chat
+//	@Description	Return the direct message chat between the current user and the given user, creating it on first use
+//	@Tags			Chats
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID	path		string			true	"User ID (UUID)"
+//	@Success		200		{object}	chatResponse	"Direct chat"
+//	@Failure		400		{object}	errorResponse	"Validation error"
+//	@Failure		401		{object}	errorResponse	"Unauthorized error"
+//	@Failure		404		{object}	errorResponse	"Data not found error"
+//	@Failure		500		{object}	errorResponse	"Internal server error"
+//	@Router			/dms/{userID} [post]
+func (handler *ChatHandler) GetOrCreateDirectChat(ctx *gin.Context) {
+	var req directChatRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	chat, err := handler.service.GetOrCreateDirectChat(ctx.Request.Context(), userID.String(), req.UserID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	rsp := newChatResponse(chat)
+	handleSuccess(ctx, rsp)
+}
+
+type getChatRequest struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// GetChat godoc
+//
+//	@Summary		Get a chat by ID
+//	@Description	Get a single chat the current user participates in
+//	@Tags			Chats
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string			true	"Chat ID (UUID)"
+//	@Success		200	{object}	chatResponse	"Chat found"
+//	@Failure		400	{object}	errorResponse	"Validation error"
+//	@Failure		401	{object}	errorResponse	"Unauthorized error"
+//	@Failure		403	{object}	errorResponse	"Forbidden error"
+//	@Failure		404	{object}	errorResponse	"Data not found error"
+//	@Failure		500	{object}	errorResponse	"Internal server error"
+//	@Router			/chats/{id} [get]
+func (handler *ChatHandler) GetChat(ctx *gin.Context) {
+	var req getChatRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	if err := checkParticipant(ctx, handler.service, req.ID, userID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	chat, err := handler.service.GetChatByID(ctx.Request.Context(), req.ID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	rsp := newChatResponse(chat)
+	handleSuccess(ctx, rsp)
+}
+
+// GetMyChats godoc
+//
+//	@Summary		List chats of the current user
+//	@Description	Get all chats the current user participates in, most recently active first
+//	@Tags			Chats
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	meta			"Chats displayed"
+//	@Failure		401	{object}	errorResponse	"Unauthorized error"
+//	@Failure		500	{object}	errorResponse	"Internal server error"
+//	@Router			/chats [get]
+func (handler *ChatHandler) GetMyChats(ctx *gin.Context) {
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	chats, err := handler.service.GetChatsByUserID(ctx.Request.Context(), userID.String())
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	chatResponses := make([]chatResponse, len(chats))
+	for i, chat := range chats {
+		chatResponses[i] = newChatResponse(&chat)
+	}
+
+	total := uint64(len(chats))
+	meta := newMeta(total, total, 0)
+	rsp := toMap(meta, chatResponses, "chats")
+
+	handleSuccess(ctx, rsp)
+}
+
+type updateChatRequest struct {
+	Name string `json:"name" binding:"required,max=100" example:"Renamed chat"`
+}
+
+// UpdateChat godoc
+//
+//	@Summary		Rename a chat
+//	@Description	Update the name of a chat; the current user must be a moderator or an admin of it
+//	@Tags			Chats
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"Chat ID (UUID)"
+//	@Param			chat	body		updateChatRequest	true	"Fields to update"
+//	@Success		200		{object}	chatResponse		"Chat updated"
+//	@Failure		400		{object}	errorResponse		"Validation error"
+//	@Failure		401		{object}	errorResponse		"Unauthorized error"
+//	@Failure		403		{object}	errorResponse		"Forbidden error"
+//	@Failure		404		{object}	errorResponse		"Data not found error"
+//	@Failure		500		{object}	errorResponse		"Internal server error"
+//	@Router			/chats/{id} [put]
+func (handler *ChatHandler) UpdateChat(ctx *gin.Context) {
+	var uri getChatRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	var req updateChatRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	if err := checkParticipant(ctx, handler.service, uri.ID, userID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	chat, err := handler.service.GetChatByID(ctx.Request.Context(), uri.ID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	chat.Name = &req.Name
+	updatedChat, err := handler.service.UpdateChat(ctx.Request.Context(), userID.String(), chat)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	rsp := newChatResponse(updatedChat)
+	handleSuccess(ctx, rsp)
+}
+
+// DeleteChat godoc
+//
+//	@Summary		Delete a chat
+//	@Description	Delete a chat for all of its participants; the current user must be an admin of it
+//	@Tags			Chats
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string			true	"Chat ID (UUID)"
+//	@Success		200	{object}	response		"Chat deleted"
+//	@Failure		400	{object}	errorResponse	"Validation error"
+//	@Failure		401	{object}	errorResponse	"Unauthorized error"
+//	@Failure		403	{object}	errorResponse	"Forbidden error"
+//	@Failure		404	{object}	errorResponse	"Data not found error"
+//	@Failure		500	{object}	errorResponse	"Internal server error"
+//	@Router			/chats/{id} [delete]
+func (handler *ChatHandler) DeleteChat(ctx *gin.Context) {
+	var req getChatRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	if err := checkParticipant(ctx, handler.service, req.ID, userID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	err = handler.service.DeleteChat(ctx.Request.Context(), userID.String(), req.ID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	handleSuccess(ctx, nil)
+}
+
+type setMessageTTLRequest struct {
+	TTL *int `json:"ttl" binding:"required,min=0,max=31536000" example:"86400"`
+}
+
+// SetMessageTTL godoc
+//
+//	@Summary		Set disappearing messages
+//	@Description	Make the messages sent from now on disappear after the given number of seconds, or keep them with 0.
+//	@Description	Either user of a direct chat and the admins of a group chat may change it; a notice is posted in the chat.
+//	@Tags			Chats
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string					true	"Chat ID (UUID)"
+//	@Param			ttl	body		setMessageTTLRequest	true	"Message TTL in seconds"
+//	@Success		200	{object}	chatResponse			"Message TTL updated"
+//	@Failure		400	{object}	errorResponse			"Validation error"
+//	@Failure		401	{object}	errorResponse			"Unauthorized error"
+//	@Failure		403	{object}	errorResponse			"Forbidden error"
+//	@Failure		404	{object}	errorResponse			"Data not found error"
+//	@Failure		500	{object}	errorResponse			"Internal server error"
+//	@Router			/chats/{id}/message-ttl [put]
+func (handler *ChatHandler) SetMessageTTL(ctx *gin.Context) {
+	var uri getChatRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	var req setMessageTTLRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	ttl := time.Duration(*req.TTL) * time.Second
+	updatedChat, err := handler.service.SetMessageTTL(ctx.Request.Context(), userID.String(), uri.ID, ttl)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	rsp := newChatResponse(updatedChat)
+	handleSuccess(ctx, rsp)
+}
+
+type saveDraftRequest struct {
+	Text             string  `json:"text" binding:"max=4096" example:"I was about to say"`
+	ReplyToMessageID *string `json:"reply_to_message_id" binding:"omitempty,uuid" example:"6b0f7d9e-2c3a-4f5b-8e1d-9a4c7b2e5f30"`
+}
+
+// SaveDraft godoc
+//
+//	@Summary		Save a draft
+//	@Description	Keep what the current user is writing in the chat so that their other devices pick it up.
+//	@Description	A draft with neither text nor reply target clears it, and sending a message to the chat clears it too.
+//	@Tags			Chats
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"Chat ID (UUID)"
+//	@Param			draft	body		saveDraftRequest	true	"Draft"
+//	@Success		200		{object}	draftResponse		"Draft saved"
+//	@Failure		400		{object}	errorResponse		"Validation error"
+//	@Failure		401		{object}	errorResponse		"Unauthorized error"
+//	@Failure		403		{object}	errorResponse		"Forbidden error"
+//	@Failure		500		{object}	errorResponse		"Internal server error"
+//	@Router			/chats/{id}/draft [put]
+func (handler *ChatHandler) SaveDraft(ctx *gin.Context) {
+	var uri getChatRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	var req saveDraftRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	if err := checkParticipant(ctx, handler.service, uri.ID, userID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	draft := &domain.ChatDraft{
+		ChatID: uuid.MustParse(uri.ID),
+		UserID: userID,
+		Text:   req.Text,
+	}
+	if req.ReplyToMessageID != nil {
+		replyToMessageID := uuid.MustParse(*req.ReplyToMessageID)
+		draft.ReplyToMessageID = &replyToMessageID
+	}
+
+	savedDraft, err := handler.service.SaveChatDraft(ctx.Request.Context(), draft)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	rsp := newDraftResponse(savedDraft)
+	handleSuccess(ctx, rsp)
+}
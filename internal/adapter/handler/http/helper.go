@@ -1,11 +1,18 @@
 package httphandler
 
 import (
+	"encoding/base64"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/HellEaglee/Golang-Chat/internal/core/port"
+	"github.com/HellEaglee/Golang-Chat/internal/core/util"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // stringToUint64 is a helper function to convert a string to uint64
@@ -20,8 +27,125 @@ func stringToUint64(str string) (uint64, error) {
 // 	return ctx.MustGet(key).(*domain.TokenPayload)
 // }
 
+// getAuthUserID is a helper function to get the authenticated user id from the context
+func getAuthUserID(ctx *gin.Context) (uuid.UUID, error) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		return uuid.Nil, util.ErrUnauthorized
+	}
+
+	id, ok := userID.(string)
+	if !ok {
+		return uuid.Nil, util.ErrUnauthorized
+	}
+
+	return uuid.Parse(id)
+}
+
+// checkParticipant is a helper function to make sure the user is an active participant of the chat
+func checkParticipant(ctx *gin.Context, service port.ChatService, chatID string, userID uuid.UUID) error {
+	_, err := service.GetChatParticipantByChatIDUserID(ctx.Request.Context(), chatID, userID.String())
+	if err != nil {
+		if err == util.ErrDataNotFound {
+			return util.ErrForbidden
+		}
+		return err
+	}
+	return nil
+}
+
+// checkModerator is a helper function to make sure the user is an admin or a moderator of the chat
+func checkModerator(ctx *gin.Context, service port.ChatService, chatID string, userID uuid.UUID) error {
+	participant, err := service.GetChatParticipantByChatIDUserID(ctx.Request.Context(), chatID, userID.String())
+	if err != nil {
+		if err == util.ErrDataNotFound {
+			return util.ErrForbidden
+		}
+		return err
+	}
+	if participant.Role != domain.ChatRoleAdmin && participant.Role != domain.ChatRoleModerator {
+		return util.ErrForbidden
+	}
+	return nil
+}
+
+// clearDraft is a helper function to drop the draft of the user once it went out as a message;
+// the message is sent either way, so failing to clear the draft is only logged
+func clearDraft(ctx *gin.Context, service port.ChatService, chatID string, userID uuid.UUID) {
+	if err := service.ClearChatDraft(ctx.Request.Context(), chatID, userID.String()); err != nil {
+		slog.Error("Error clearing draft", "chat_id", chatID, "user_id", userID, "error", err)
+	}
+}
+
+// encodeMessageCursor is a helper function to turn a message position into an opaque cursor
+func encodeMessageCursor(message *domain.Message) string {
+	raw := message.CreatedAt.Format(time.RFC3339Nano) + "|" + message.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeMessageCursor is a helper function to parse a cursor produced by encodeMessageCursor
+func decodeMessageCursor(cursor string) (*domain.MessageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, util.ErrInvalidCursor
+	}
+	return parseMessagePosition(string(raw))
+}
+
+// parseMessagePosition is a helper function to parse the "created_at|id" part of the cursors
+func parseMessagePosition(raw string) (*domain.MessageCursor, error) {
+	createdAtStr, idStr, found := strings.Cut(raw, "|")
+	if !found {
+		return nil, util.ErrInvalidCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtStr)
+	if err != nil {
+		return nil, util.ErrInvalidCursor
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return nil, util.ErrInvalidCursor
+	}
+
+	return &domain.MessageCursor{CreatedAt: createdAt, ID: id}, nil
+}
+
+// encodeSearchCursor is a helper function to turn the position of a search result into an opaque cursor
+func encodeSearchCursor(result *domain.MessageSearchResult) string {
+	rank := strconv.FormatFloat(float64(result.Rank), 'g', -1, 32)
+	raw := rank + "|" + result.CreatedAt.Format(time.RFC3339Nano) + "|" + result.MessageID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeSearchCursor is a helper function to parse a cursor produced by encodeSearchCursor
+func decodeSearchCursor(cursor string) (*domain.SearchCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, util.ErrInvalidCursor
+	}
+
+	rankStr, position, found := strings.Cut(string(raw), "|")
+	if !found {
+		return nil, util.ErrInvalidCursor
+	}
+
+	rank, err := strconv.ParseFloat(rankStr, 32)
+	if err != nil {
+		return nil, util.ErrInvalidCursor
+	}
+
+	messagePosition, err := parseMessagePosition(position)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.SearchCursor{Rank: float32(rank), CreatedAt: messagePosition.CreatedAt, ID: messagePosition.ID}, nil
+}
+
 // toMap is a helper function to add meta and data to a map
-func toMap(m meta, data any, key string) map[string]any {
+func toMap(m any, data any, key string) map[string]any {
 	return map[string]any{
 		"meta": m,
 		key:    data,
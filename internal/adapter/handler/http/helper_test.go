@@ -0,0 +1,97 @@
+package httphandler
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/HellEaglee/Golang-Chat/internal/core/util"
+	"github.com/google/uuid"
+)
+
+func rawCursor(raw string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func TestMessageCursorRoundTrip(t *testing.T) {
+	tests := []time.Time{
+		time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC),
+		// Postgres keeps microseconds, which the cursor must not round
+		time.Date(2024, 3, 1, 12, 30, 0, 123456000, time.UTC),
+		time.Date(2024, 3, 1, 12, 30, 0, 1, time.FixedZone("UTC+5", 5*60*60)),
+	}
+
+	for _, createdAt := range tests {
+		message := &domain.Message{ID: uuid.New(), CreatedAt: createdAt}
+		cursor, err := decodeMessageCursor(encodeMessageCursor(message))
+		if err != nil {
+			t.Fatalf("decoding the cursor of %v: %v", createdAt, err)
+		}
+		if !cursor.CreatedAt.Equal(createdAt) || cursor.ID != message.ID {
+			t.Errorf("cursor = %v %v, want %v %v", cursor.CreatedAt, cursor.ID, createdAt, message.ID)
+		}
+	}
+}
+
+func TestDecodeMessageCursorRejects(t *testing.T) {
+	id := uuid.NewString()
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "empty", cursor: ""},
+		{name: "not base64", cursor: "not a cursor!"},
+		{name: "no separator", cursor: rawCursor("2024-03-01T12:30:00Z")},
+		{name: "bad time", cursor: rawCursor("yesterday|" + id)},
+		{name: "time without zone", cursor: rawCursor("2024-03-01T12:30:00|" + id)},
+		{name: "bad id", cursor: rawCursor("2024-03-01T12:30:00Z|42")},
+		{name: "extra part", cursor: rawCursor("2024-03-01T12:30:00Z|" + id + "|more")},
+	}
+
+	for _, tt := range tests {
+		if cursor, err := decodeMessageCursor(tt.cursor); err != util.ErrInvalidCursor {
+			t.Errorf("%s: decodeMessageCursor = %v, %v, want %v", tt.name, cursor, err, util.ErrInvalidCursor)
+		}
+	}
+}
+
+func TestSearchCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 12, 30, 0, 123456000, time.UTC)
+
+	// ranks are compared with what Postgres computed, so they must come back bit for bit
+	for _, rank := range []float32{0, 0.1, 0.0607927, 1e-20, 1.5} {
+		result := &domain.MessageSearchResult{MessageID: uuid.New(), CreatedAt: createdAt, Rank: rank}
+		cursor, err := decodeSearchCursor(encodeSearchCursor(result))
+		if err != nil {
+			t.Fatalf("decoding the cursor of rank %v: %v", rank, err)
+		}
+		if cursor.Rank != rank || !cursor.CreatedAt.Equal(createdAt) || cursor.ID != result.MessageID {
+			t.Errorf("cursor = %v %v %v, want %v %v %v", cursor.Rank, cursor.CreatedAt, cursor.ID, rank, createdAt, result.MessageID)
+		}
+	}
+}
+
+func TestDecodeSearchCursorRejects(t *testing.T) {
+	id := uuid.NewString()
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "empty", cursor: ""},
+		{name: "not base64", cursor: "not a cursor!"},
+		{name: "message cursor", cursor: rawCursor("2024-03-01T12:30:00Z|" + id)},
+		{name: "bad rank", cursor: rawCursor("high|2024-03-01T12:30:00Z|" + id)},
+		{name: "missing id", cursor: rawCursor("0.5|2024-03-01T12:30:00Z")},
+		{name: "bad time", cursor: rawCursor("0.5|yesterday|" + id)},
+		{name: "bad id", cursor: rawCursor("0.5|2024-03-01T12:30:00Z|42")},
+	}
+
+	for _, tt := range tests {
+		if cursor, err := decodeSearchCursor(tt.cursor); err != util.ErrInvalidCursor {
+			t.Errorf("%s: decodeSearchCursor = %v, %v, want %v", tt.name, cursor, err, util.ErrInvalidCursor)
+		}
+	}
+}
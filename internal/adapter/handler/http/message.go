@@ -0,0 +1,1223 @@
+package httphandler
+
+import (
+	"context"
+	"time"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/HellEaglee/Golang-Chat/internal/core/port"
+	"github.com/HellEaglee/Golang-Chat/internal/core/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type MessageHandler struct {
+	service     port.MessageService
+	chatService port.ChatService
+}
+
+func NewMessageHandler(service port.MessageService, chatService port.ChatService) *MessageHandler {
+	return &MessageHandler{service: service, chatService: chatService}
+}
+
+type chatMessagesRequest struct {
+	ChatID string `uri:"id" binding:"required,uuid"`
+}
+
+type chatMessageRequest struct {
+	ChatID    string `uri:"id" binding:"required,uuid"`
+	MessageID string `uri:"messageID" binding:"required,uuid"`
+}
+
+// getChatMessage loads a message and makes sure it belongs to the chat from the path
+func (handler *MessageHandler) getChatMessage(ctx *gin.Context, chatID, messageID string) (*domain.Message, error) {
+	message, err := handler.service.GetMessage(ctx.Request.Context(), messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message.ChatID.String() != chatID {
+		return nil, util.ErrDataNotFound
+	}
+	return message, nil
+}
+
+// The text limit leaves room for markup; the parsed text may not be longer than 4096 characters
+type sendMessageRequest struct {
+	Text             string   `json:"text" binding:"required_without=AttachmentIDs,max=8192" example:"Hello **there**"`
+	Format           string   `json:"format" binding:"omitempty,oneof=plain markdown" example:"markdown"`
+	ReplyToMessageID *string  `json:"reply_to_message_id" binding:"omitempty,uuid" example:"6b0f7d9e-2c3a-4f5b-8e1d-9a4c7b2e5f30"`
+	AttachmentIDs    []string `json:"attachment_ids" binding:"omitempty,max=10,unique,dive,uuid" example:"0f8e7d6c-5b4a-4392-8170-6f5e4d3c2b1a"`
+}
+
+// SendMessage godoc
+//
+//	@Summary		Send a message
+//	@Description	Send a message to a chat the current user participates in, optionally with previously uploaded attachments.
+//	@Description	Text in the markdown format may use bold, italic, inline code, fenced code blocks, links and quotes;
+//	@Description	it is stored as plain text with entities marking the formatted spans.
+//	@Tags			Messages
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"Chat ID (UUID)"
+//	@Param			message	body		sendMessageRequest	true	"Send message request"
+//	@Success		200		{object}	messageResponse		"Message sent"
+//	@Failure		400		{object}	errorResponse		"Validation error"
+//	@Failure		401		{object}	errorResponse		"Unauthorized error"
+//	@Failure		403		{object}	errorResponse		"Forbidden error"
+//	@Failure		500		{object}	errorResponse		"Internal server error"
+//	@Router			/chats/{id}/messages [post]
+func (handler *MessageHandler) SendMessage(ctx *gin.Context) {
+	var uri chatMessagesRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	var req sendMessageRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	if err := checkParticipant(ctx, handler.chatService, uri.ChatID, userID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	message := &domain.Message{
+		ID:     uuid.New(),
+		ChatID: uuid.MustParse(uri.ChatID),
+		UserID: userID,
+		Text:   req.Text,
+		Format: req.Format,
+	}
+	if req.ReplyToMessageID != nil {
+		replyToMessageID := uuid.MustParse(*req.ReplyToMessageID)
+		message.ReplyToMessageID = &replyToMessageID
+	}
+	for _, attachmentID := range req.AttachmentIDs {
+		message.Attachments = append(message.Attachments, domain.Attachment{ID: uuid.MustParse(attachmentID)})
+	}
+
+	createdMessage, err := handler.service.CreateMessage(ctx.Request.Context(), message)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	clearDraft(ctx, handler.chatService, uri.ChatID, userID)
+
+	rsp := newMessageResponse(createdMessage)
+	handleSuccess(ctx, rsp)
+}
+
+type getMessagesRequest struct {
+	Before string `form:"before" binding:"omitempty,excluded_with=After" example:"MjAyNS0wMS0wMVQwMDowMDowMFp8M2U0"`
+	After  string `form:"after" binding:"omitempty" example:"MjAyNS0wMS0wMVQwMDowMDowMFp8M2U0"`
+	Limit  uint64 `form:"limit" binding:"omitempty,min=1,max=100" example:"50"`
+}
+
+// GetMessages godoc
+//
+//	@Summary		List chat messages
+//	@Description	Get a page of chat history in chronological order. Without cursors the newest messages are returned;
+//	@Description	pass prev_cursor as before to load older messages or next_cursor as after to load newer ones.
+//	@Tags			Messages
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string			true	"Chat ID (UUID)"
+//	@Param			before	query		string			false	"Return messages older than this cursor"
+//	@Param			after	query		string			false	"Return messages newer than this cursor"
+//	@Param			limit	query		int				false	"Page size"	minimum(1)	maximum(100)	default(50)
+//	@Success		200		{object}	cursorMeta		"Messages displayed"
+//	@Failure		400		{object}	errorResponse	"Validation error"
+//	@Failure		401		{object}	errorResponse	"Unauthorized error"
+//	@Failure		403		{object}	errorResponse	"Forbidden error"
+//	@Failure		500		{object}	errorResponse	"Internal server error"
+//	@Router			/chats/{id}/messages [get]
+func (handler *MessageHandler) GetMessages(ctx *gin.Context) {
+	var uri chatMessagesRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	var req getMessagesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	if err := checkParticipant(ctx, handler.chatService, uri.ChatID, userID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	page := domain.MessagePage{Limit: int(req.Limit)}
+	if req.Before != "" {
+		page.Before, err = decodeMessageCursor(req.Before)
+	} else if req.After != "" {
+		page.After, err = decodeMessageCursor(req.After)
+	}
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	messages, hasMore, err := handler.service.GetMessagesByChatID(ctx.Request.Context(), uri.ChatID, page)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	if err := handler.service.LoadReactions(ctx.Request.Context(), messages, userID.String()); err != nil {
+		handleError(ctx, err)
+		return
+	}
+	if err := handler.service.LoadThreadSummaries(ctx.Request.Context(), messages); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	messageResponses := make([]messageResponse, len(messages))
+	for i, message := range messages {
+		messageResponses[i] = newMessageResponse(&message)
+	}
+
+	var prevCursor, nextCursor string
+	if len(messages) > 0 {
+		prevCursor = encodeMessageCursor(&messages[0])
+		nextCursor = encodeMessageCursor(&messages[len(messages)-1])
+	}
+
+	meta := newCursorMeta(uint64(len(messages)), hasMore, prevCursor, nextCursor)
+	rsp := toMap(meta, messageResponses, "messages")
+
+	handleSuccess(ctx, rsp)
+}
+
+type getThreadRequest struct {
+	Before string `form:"before" binding:"omitempty,excluded_with=After" example:"MjAyNS0wMS0wMVQwMDowMDowMFp8M2U0"`
+	After  string `form:"after" binding:"omitempty" example:"MjAyNS0wMS0wMVQwMDowMDowMFp8M2U0"`
+	Limit  uint64 `form:"limit" binding:"omitempty,min=1,max=100" example:"50"`
+}
+
+// GetThread godoc
+//
+//	@Summary		Get a message thread
+//	@Description	Get a message along with a page of its direct replies in chronological order, paginated like the chat history
+//	@Tags			Messages
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string			true	"Chat ID (UUID)"
+//	@Param			messageID	path		string			true	"Root message ID (UUID)"
+//	@Param			before		query		string			false	"Return replies older than this cursor"
+//	@Param			after		query		string			false	"Return replies newer than this cursor"
+//	@Param			limit		query		int				false	"Page size"	minimum(1)	maximum(100)	default(50)
+//	@Success		200			{object}	cursorMeta		"Thread displayed"
+//	@Failure		400			{object}	errorResponse	"Validation error"
+//	@Failure		401			{object}	errorResponse	"Unauthorized error"
+//	@Failure		403			{object}	errorResponse	"Forbidden error"
+//	@Failure		404			{object}	errorResponse	"Data not found error"
+//	@Failure		500			{object}	errorResponse	"Internal server error"
+//	@Router			/chats/{id}/messages/{messageID}/thread [get]
+func (handler *MessageHandler) GetThread(ctx *gin.Context) {
+	var uri chatMessageRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	var req getThreadRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	if err := checkParticipant(ctx, handler.chatService, uri.ChatID, userID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	root, err := handler.getChatMessage(ctx, uri.ChatID, uri.MessageID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	page := domain.MessagePage{Limit: int(req.Limit)}
+	if req.Before != "" {
+		page.Before, err = decodeMessageCursor(req.Before)
+	} else if req.After != "" {
+		page.After, err = decodeMessageCursor(req.After)
+	}
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	replies, hasMore, err := handler.service.GetThreadReplies(ctx.Request.Context(), root, page)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	// the root goes first so its reactions and summary are loaded in the same queries as the replies
+	messages := append([]domain.Message{*root}, replies...)
+	if err := handler.service.LoadReactions(ctx.Request.Context(), messages, userID.String()); err != nil {
+		handleError(ctx, err)
+		return
+	}
+	if err := handler.service.LoadThreadSummaries(ctx.Request.Context(), messages); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	replyResponses := make([]messageResponse, len(replies))
+	for i, reply := range messages[1:] {
+		replyResponses[i] = newMessageResponse(&reply)
+	}
+
+	var prevCursor, nextCursor string
+	if len(replies) > 0 {
+		prevCursor = encodeMessageCursor(&replies[0])
+		nextCursor = encodeMessageCursor(&replies[len(replies)-1])
+	}
+
+	meta := newCursorMeta(uint64(len(replies)), hasMore, prevCursor, nextCursor)
+	rsp := toMap(meta, replyResponses, "replies")
+	rsp["root"] = newMessageResponse(&messages[0])
+
+	handleSuccess(ctx, rsp)
+}
+
+type syncMessagesRequest struct {
+	AfterSeq int64  `form:"after_seq" binding:"min=0" example:"41"`
+	Limit    uint64 `form:"limit" binding:"omitempty,min=1,max=100" example:"100"`
+}
+
+// SyncMessages godoc
+//
+//	@Summary		Replay chat messages by sequence
+//	@Description	Get the messages of a chat whose sequence number is greater than after_seq, in sequence order. Every message
+//	@Description	gets the next number of its chat, so a reconnecting client can pass the last seq it saw and receive exactly
+//	@Description	what it missed. Deleted messages are included with is_deleted set so the sequence has no gaps; expired
+//	@Description	messages come back with is_deleted set under a new seq once they are removed. Keep calling with meta.last_seq
+//	@Description	while has_more is true.
+//	@Tags			Messages
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string			true	"Chat ID (UUID)"
+//	@Param			after_seq	query		int				false	"Return messages after this sequence number"	minimum(0)	default(0)
+//	@Param			limit		query		int				false	"Page size"										minimum(1)	maximum(100)	default(100)
+//	@Success		200			{object}	seqMeta			"Messages displayed"
+//	@Failure		400			{object}	errorResponse	"Validation error"
+//	@Failure		401			{object}	errorResponse	"Unauthorized error"
+//	@Failure		403			{object}	errorResponse	"Forbidden error"
+//	@Failure		500			{object}	errorResponse	"Internal server error"
+//	@Router			/chats/{id}/messages/sync [get]
+func (handler *MessageHandler) SyncMessages(ctx *gin.Context) {
+	var uri chatMessagesRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	var req syncMessagesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	if err := checkParticipant(ctx, handler.chatService, uri.ChatID, userID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	messages, hasMore, err := handler.service.GetMessagesAfterSeq(ctx.Request.Context(), uri.ChatID, req.AfterSeq, int(req.Limit))
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	if err := handler.service.LoadReactions(ctx.Request.Context(), messages, userID.String()); err != nil {
+		handleError(ctx, err)
+		return
+	}
+	if err := handler.service.LoadThreadSummaries(ctx.Request.Context(), messages); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	messageResponses := make([]messageResponse, len(messages))
+	for i, message := range messages {
+		messageResponses[i] = newMessageResponse(&message)
+	}
+
+	lastSeq := req.AfterSeq
+	if len(messages) > 0 {
+		lastSeq = messages[len(messages)-1].Seq
+	}
+
+	meta := newSeqMeta(uint64(len(messages)), hasMore, lastSeq)
+	rsp := toMap(meta, messageResponses, "messages")
+
+	handleSuccess(ctx, rsp)
+}
+
+type updateMessageRequest struct {
+	Text   string `json:"text" binding:"required,max=8192" example:"Hello there, *edited*"`
+	Format string `json:"format" binding:"omitempty,oneof=plain markdown" example:"markdown"`
+}
+
+// UpdateMessage godoc
+//
+//	@Summary		Edit a message
+//	@Description	Edit the text of a message sent by the current user. The previous text is kept in the edit history,
+//	@Description	and messages older than the configured edit window can no longer be edited. Link previews follow the links
+//	@Description	of the new text, and those of added links arrive with a message.previewed event.
+//	@Tags			Messages
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string					true	"Chat ID (UUID)"
+//	@Param			messageID	path		string					true	"Message ID (UUID)"
+//	@Param			message		body		updateMessageRequest	true	"Fields to update"
+//	@Success		200			{object}	messageResponse			"Message updated"
+//	@Failure		400			{object}	errorResponse			"Validation error"
+//	@Failure		401			{object}	errorResponse			"Unauthorized error"
+//	@Failure		403			{object}	errorResponse			"Forbidden error"
+//	@Failure		404			{object}	errorResponse			"Data not found error"
+//	@Failure		500			{object}	errorResponse			"Internal server error"
+//	@Router			/chats/{id}/messages/{messageID} [put]
+func (handler *MessageHandler) UpdateMessage(ctx *gin.Context) {
+	var uri chatMessageRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	var req updateMessageRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	if err := checkParticipant(ctx, handler.chatService, uri.ChatID, userID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	message, err := handler.getChatMessage(ctx, uri.ChatID, uri.MessageID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	if message.UserID != userID {
+		handleError(ctx, util.ErrForbidden)
+		return
+	}
+
+	message.Text, message.Format = req.Text, req.Format
+	updatedMessage, err := handler.service.UpdateMessage(ctx.Request.Context(), message)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	rsp := newMessageResponse(updatedMessage)
+	handleSuccess(ctx, rsp)
+}
+
+type forwardMessagesRequest struct {
+	MessageIDs []string `json:"message_ids" binding:"required,min=1,max=100,unique,dive,uuid" example:"6b0f7d9e-2c3a-4f5b-8e1d-9a4c7b2e5f30"`
+	ChatIDs    []string `json:"chat_ids" binding:"required,min=1,max=10,unique,dive,uuid" example:"a7c8e3b1-5f0d-4d1e-9a5c-2b7f3e6d8c91"`
+}
+
+// ForwardMessages godoc
+//
+//	@Summary		Forward messages
+//	@Description	Forward messages of the chat to other chats the current user participates in, keeping where they came from.
+//	@Description	Attachments are shared with the original messages instead of being uploaded again. Either every copy is sent or none.
+//	@Tags			Messages
+//	@Accept			json
+//	@Produce		json
+//	@Param			id						path		string					true	"Chat ID (UUID)"
+//	@Param			forwardMessagesRequest	body		forwardMessagesRequest	true	"Messages and target chats"
+//	@Success		200						{array}		messageResponse			"Messages forwarded"
+//	@Failure		400						{object}	errorResponse			"Validation error"
+//	@Failure		401						{object}	errorResponse			"Unauthorized error"
+//	@Failure		403						{object}	errorResponse			"Forbidden error"
+//	@Failure		500						{object}	errorResponse			"Internal server error"
+//	@Router			/chats/{id}/messages/forward [post]
+func (handler *MessageHandler) ForwardMessages(ctx *gin.Context) {
+	var uri chatMessagesRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	var req forwardMessagesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	if err := checkParticipant(ctx, handler.chatService, uri.ChatID, userID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	messageIDs := make([]uuid.UUID, len(req.MessageIDs))
+	for i, messageID := range req.MessageIDs {
+		messageIDs[i] = uuid.MustParse(messageID)
+	}
+	chatIDs := make([]uuid.UUID, len(req.ChatIDs))
+	for i, chatID := range req.ChatIDs {
+		chatIDs[i] = uuid.MustParse(chatID)
+	}
+
+	messages, err := handler.service.ForwardMessages(ctx.Request.Context(), userID.String(), uri.ChatID, messageIDs, chatIDs)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	rsp := make([]messageResponse, len(messages))
+	for i, message := range messages {
+		rsp[i] = newMessageResponse(&message)
+	}
+	handleSuccess(ctx, rsp)
+}
+
+// GetMessageEdits godoc
+//
+//	@Summary		Get message edit history
+//	@Description	Get the previous versions of a message, oldest first. Each entry holds the text as it was before an edit.
+//	@Tags			Messages
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string				true	"Chat ID (UUID)"
+//	@Param			messageID	path		string				true	"Message ID (UUID)"
+//	@Success		200			{array}		messageEditResponse	"Edit history"
+//	@Failure		400			{object}	errorResponse		"Validation error"
+//	@Failure		401			{object}	errorResponse		"Unauthorized error"
+//	@Failure		403			{object}	errorResponse		"Forbidden error"
+//	@Failure		404			{object}	errorResponse		"Data not found error"
+//	@Failure		500			{object}	errorResponse		"Internal server error"
+//	@Router			/chats/{id}/messages/{messageID}/edits [get]
+func (handler *MessageHandler) GetMessageEdits(ctx *gin.Context) {
+	var uri chatMessageRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	if err := checkParticipant(ctx, handler.chatService, uri.ChatID, userID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	message, err := handler.getChatMessage(ctx, uri.ChatID, uri.MessageID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	edits, err := handler.service.GetMessageEdits(ctx.Request.Context(), message.ID.String())
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	editResponses := make([]messageEditResponse, len(edits))
+	for i, edit := range edits {
+		editResponses[i] = newMessageEditResponse(&edit)
+	}
+
+	handleSuccess(ctx, editResponses)
+}
+
+type renderMessageRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=html plain" example:"html"`
+}
+
+// RenderMessage godoc
+//
+//	@Summary		Render a message
+//	@Description	Render the text of a message with its formatting as HTML, with everything the sender wrote escaped,
+//	@Description	or as plain text. HTML is the default.
+//	@Tags			Messages
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string					true	"Chat ID (UUID)"
+//	@Param			messageID	path		string					true	"Message ID (UUID)"
+//	@Param			format		query		string					false	"Output format"	Enums(html, plain)
+//	@Success		200			{object}	renderedMessageResponse	"Rendered message"
+//	@Failure		400			{object}	errorResponse			"Validation error"
+//	@Failure		401			{object}	errorResponse			"Unauthorized error"
+//	@Failure		403			{object}	errorResponse			"Forbidden error"
+//	@Failure		404			{object}	errorResponse			"Data not found error"
+//	@Failure		500			{object}	errorResponse			"Internal server error"
+//	@Router			/chats/{id}/messages/{messageID}/render [get]
+func (handler *MessageHandler) RenderMessage(ctx *gin.Context) {
+	var uri chatMessageRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	var req renderMessageRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+	if req.Format == "" {
+		req.Format = domain.MessageFormatHTML
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	if err := checkParticipant(ctx, handler.chatService, uri.ChatID, userID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	message, err := handler.getChatMessage(ctx, uri.ChatID, uri.MessageID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	content, err := handler.service.RenderMessage(message, req.Format)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	rsp := renderedMessageResponse{
+		MessageID: message.ID,
+		Format:    req.Format,
+		Content:   content,
+	}
+	handleSuccess(ctx, rsp)
+}
+
+// DeleteMessage godoc
+//
+//	@Summary		Delete a message
+//	@Description	Delete a message sent by the current user; a pinned message is unpinned as well
+//	@Tags			Messages
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string			true	"Chat ID (UUID)"
+//	@Param			messageID	path		string			true	"Message ID (UUID)"
+//	@Success		200			{object}	response		"Message deleted"
+//	@Failure		400			{object}	errorResponse	"Validation error"
+//	@Failure		401			{object}	errorResponse	"Unauthorized error"
+//	@Failure		403			{object}	errorResponse	"Forbidden error"
+//	@Failure		404			{object}	errorResponse	"Data not found error"
+//	@Failure		500			{object}	errorResponse	"Internal server error"
+//	@Router			/chats/{id}/messages/{messageID} [delete]
+func (handler *MessageHandler) DeleteMessage(ctx *gin.Context) {
+	var uri chatMessageRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	if err := checkParticipant(ctx, handler.chatService, uri.ChatID, userID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	message, err := handler.getChatMessage(ctx, uri.ChatID, uri.MessageID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	if message.UserID != userID {
+		handleError(ctx, util.ErrForbidden)
+		return
+	}
+
+	err = handler.service.DeleteMessage(ctx.Request.Context(), uri.MessageID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	handleSuccess(ctx, nil)
+}
+
+// MarkDelivered godoc
+//
+//	@Summary		Acknowledge delivery
+//	@Description	Mark the message and every earlier message of the chat from other users as delivered to the current user
+//	@Tags			Messages
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string			true	"Chat ID (UUID)"
+//	@Param			messageID	path		string			true	"Message ID (UUID)"
+//	@Success		200			{object}	response		"Delivery acknowledged"
+//	@Failure		400			{object}	errorResponse	"Validation error"
+//	@Failure		401			{object}	errorResponse	"Unauthorized error"
+//	@Failure		403			{object}	errorResponse	"Forbidden error"
+//	@Failure		404			{object}	errorResponse	"Data not found error"
+//	@Failure		500			{object}	errorResponse	"Internal server error"
+//	@Router			/chats/{id}/messages/{messageID}/delivered [post]
+func (handler *MessageHandler) MarkDelivered(ctx *gin.Context) {
+	handler.acknowledge(ctx, handler.service.MarkDelivered)
+}
+
+// MarkRead godoc
+//
+//	@Summary		Acknowledge reading
+//	@Description	Mark the message and every earlier message of the chat from other users as read by the current user
+//	@Tags			Messages
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string			true	"Chat ID (UUID)"
+//	@Param			messageID	path		string			true	"Message ID (UUID)"
+//	@Success		200			{object}	response		"Read acknowledged"
+//	@Failure		400			{object}	errorResponse	"Validation error"
+//	@Failure		401			{object}	errorResponse	"Unauthorized error"
+//	@Failure		403			{object}	errorResponse	"Forbidden error"
+//	@Failure		404			{object}	errorResponse	"Data not found error"
+//	@Failure		500			{object}	errorResponse	"Internal server error"
+//	@Router			/chats/{id}/messages/{messageID}/read [post]
+func (handler *MessageHandler) MarkRead(ctx *gin.Context) {
+	handler.acknowledge(ctx, handler.service.MarkRead)
+}
+
+// acknowledge is a helper function shared by the delivery and read acknowledgements
+func (handler *MessageHandler) acknowledge(ctx *gin.Context, mark func(context.Context, *domain.Message, string) error) {
+	var uri chatMessageRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	if err := checkParticipant(ctx, handler.chatService, uri.ChatID, userID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	message, err := handler.getChatMessage(ctx, uri.ChatID, uri.MessageID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	if err := mark(ctx.Request.Context(), message, userID.String()); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	handleSuccess(ctx, nil)
+}
+
+// GetMessageStatus godoc
+//
+//	@Summary		Get message delivery status
+//	@Description	Get the sent, delivered or read state of a message for each recipient and overall. Only the sender can see it;
+//	@Description	in group chats the overall status is delivered or read once every participant got there.
+//	@Tags			Messages
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string					true	"Chat ID (UUID)"
+//	@Param			messageID	path		string					true	"Message ID (UUID)"
+//	@Success		200			{object}	messageStatusResponse	"Message status"
+//	@Failure		400			{object}	errorResponse			"Validation error"
+//	@Failure		401			{object}	errorResponse			"Unauthorized error"
+//	@Failure		403			{object}	errorResponse			"Forbidden error"
+//	@Failure		404			{object}	errorResponse			"Data not found error"
+//	@Failure		500			{object}	errorResponse			"Internal server error"
+//	@Router			/chats/{id}/messages/{messageID}/status [get]
+func (handler *MessageHandler) GetMessageStatus(ctx *gin.Context) {
+	var uri chatMessageRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	if err := checkParticipant(ctx, handler.chatService, uri.ChatID, userID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	message, err := handler.getChatMessage(ctx, uri.ChatID, uri.MessageID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	if message.UserID != userID {
+		handleError(ctx, util.ErrForbidden)
+		return
+	}
+
+	status, err := handler.service.GetMessageStatus(ctx.Request.Context(), message)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	rsp := newMessageStatusResponse(status)
+	handleSuccess(ctx, rsp)
+}
+
+type addReactionRequest struct {
+	Emoji string `json:"emoji" binding:"required,max=32" example:"👍"`
+}
+
+// AddReaction godoc
+//
+//	@Summary		React to a message
+//	@Description	Add an emoji reaction of the current user to a message; returns the updated reaction counts of the message
+//	@Tags			Messages
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string				true	"Chat ID (UUID)"
+//	@Param			messageID	path		string				true	"Message ID (UUID)"
+//	@Param			reaction	body		addReactionRequest	true	"Add reaction request"
+//	@Success		200			{array}		reactionResponse	"Reaction added"
+//	@Failure		400			{object}	errorResponse		"Validation error"
+//	@Failure		401			{object}	errorResponse		"Unauthorized error"
+//	@Failure		403			{object}	errorResponse		"Forbidden error"
+//	@Failure		404			{object}	errorResponse		"Data not found error"
+//	@Failure		409			{object}	errorResponse		"Data conflict error"
+//	@Failure		500			{object}	errorResponse		"Internal server error"
+//	@Router			/chats/{id}/messages/{messageID}/reactions [post]
+func (handler *MessageHandler) AddReaction(ctx *gin.Context) {
+	var uri chatMessageRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	var req addReactionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	handler.react(ctx, uri, req.Emoji, handler.service.AddReaction)
+}
+
+type reactionRequest struct {
+	ChatID    string `uri:"id" binding:"required,uuid"`
+	MessageID string `uri:"messageID" binding:"required,uuid"`
+	Emoji     string `uri:"emoji" binding:"required,max=32"`
+}
+
+// RemoveReaction godoc
+//
+//	@Summary		Remove a reaction
+//	@Description	Remove an emoji reaction of the current user from a message; returns the updated reaction counts of the message
+//	@Tags			Messages
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string				true	"Chat ID (UUID)"
+//	@Param			messageID	path		string				true	"Message ID (UUID)"
+//	@Param			emoji		path		string				true	"Emoji (URL encoded)"
+//	@Success		200			{array}		reactionResponse	"Reaction removed"
+//	@Failure		400			{object}	errorResponse		"Validation error"
+//	@Failure		401			{object}	errorResponse		"Unauthorized error"
+//	@Failure		403			{object}	errorResponse		"Forbidden error"
+//	@Failure		404			{object}	errorResponse		"Data not found error"
+//	@Failure		500			{object}	errorResponse		"Internal server error"
+//	@Router			/chats/{id}/messages/{messageID}/reactions/{emoji} [delete]
+func (handler *MessageHandler) RemoveReaction(ctx *gin.Context) {
+	var req reactionRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	handler.react(ctx, chatMessageRequest{ChatID: req.ChatID, MessageID: req.MessageID}, req.Emoji, handler.service.RemoveReaction)
+}
+
+// react is a helper function shared by adding and removing reactions
+func (handler *MessageHandler) react(ctx *gin.Context, uri chatMessageRequest, emoji string, apply func(context.Context, *domain.Message, string, string) ([]domain.ReactionCount, error)) {
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	if err := checkParticipant(ctx, handler.chatService, uri.ChatID, userID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	message, err := handler.getChatMessage(ctx, uri.ChatID, uri.MessageID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	counts, err := apply(ctx.Request.Context(), message, userID.String(), emoji)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	rsp := newReactionResponses(counts)
+	handleSuccess(ctx, rsp)
+}
+
+// PinMessage godoc
+//
+//	@Summary		Pin a message
+//	@Description	Pin a message of the chat; only admins and moderators may pin
+//	@Tags			Messages
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string					true	"Chat ID (UUID)"
+//	@Param			messageID	path		string					true	"Message ID (UUID)"
+//	@Success		200			{object}	pinnedMessageResponse	"Message pinned"
+//	@Failure		400			{object}	errorResponse			"Validation error"
+//	@Failure		401			{object}	errorResponse			"Unauthorized error"
+//	@Failure		403			{object}	errorResponse			"Forbidden error"
+//	@Failure		404			{object}	errorResponse			"Data not found error"
+//	@Failure		409			{object}	errorResponse			"Data conflict error"
+//	@Failure		500			{object}	errorResponse			"Internal server error"
+//	@Router			/chats/{id}/messages/{messageID}/pin [post]
+func (handler *MessageHandler) PinMessage(ctx *gin.Context) {
+	message, userID, ok := handler.getModeratedMessage(ctx)
+	if !ok {
+		return
+	}
+
+	pin, err := handler.service.PinMessage(ctx.Request.Context(), message, userID.String())
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	rsp := newPinnedMessageResponse(pin)
+	handleSuccess(ctx, rsp)
+}
+
+// UnpinMessage godoc
+//
+//	@Summary		Unpin a message
+//	@Description	Unpin a message of the chat; only admins and moderators may unpin
+//	@Tags			Messages
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string			true	"Chat ID (UUID)"
+//	@Param			messageID	path		string			true	"Message ID (UUID)"
+//	@Success		200			{object}	response		"Message unpinned"
+//	@Failure		400			{object}	errorResponse	"Validation error"
+//	@Failure		401			{object}	errorResponse	"Unauthorized error"
+//	@Failure		403			{object}	errorResponse	"Forbidden error"
+//	@Failure		404			{object}	errorResponse	"Data not found error"
+//	@Failure		500			{object}	errorResponse	"Internal server error"
+//	@Router			/chats/{id}/messages/{messageID}/pin [delete]
+func (handler *MessageHandler) UnpinMessage(ctx *gin.Context) {
+	message, userID, ok := handler.getModeratedMessage(ctx)
+	if !ok {
+		return
+	}
+
+	if err := handler.service.UnpinMessage(ctx.Request.Context(), message, userID.String()); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	handleSuccess(ctx, nil)
+}
+
+// getModeratedMessage is a helper function shared by pinning and unpinning; it writes the error response itself
+func (handler *MessageHandler) getModeratedMessage(ctx *gin.Context) (*domain.Message, uuid.UUID, bool) {
+	var uri chatMessageRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		validationError(ctx, err)
+		return nil, uuid.Nil, false
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return nil, uuid.Nil, false
+	}
+
+	if err := checkModerator(ctx, handler.chatService, uri.ChatID, userID); err != nil {
+		handleError(ctx, err)
+		return nil, uuid.Nil, false
+	}
+
+	message, err := handler.getChatMessage(ctx, uri.ChatID, uri.MessageID)
+	if err != nil {
+		handleError(ctx, err)
+		return nil, uuid.Nil, false
+	}
+	return message, userID, true
+}
+
+// GetPinnedMessages godoc
+//
+//	@Summary		List pinned messages
+//	@Description	Get the pinned messages of a chat, most recently pinned first
+//	@Tags			Messages
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string					true	"Chat ID (UUID)"
+//	@Success		200	{array}		pinnedMessageResponse	"Pinned messages displayed"
+//	@Failure		400	{object}	errorResponse			"Validation error"
+//	@Failure		401	{object}	errorResponse			"Unauthorized error"
+//	@Failure		403	{object}	errorResponse			"Forbidden error"
+//	@Failure		500	{object}	errorResponse			"Internal server error"
+//	@Router			/chats/{id}/pins [get]
+func (handler *MessageHandler) GetPinnedMessages(ctx *gin.Context) {
+	var uri chatMessagesRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	if err := checkParticipant(ctx, handler.chatService, uri.ChatID, userID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	pins, err := handler.service.GetPinnedMessages(ctx.Request.Context(), uri.ChatID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	pinResponses := make([]pinnedMessageResponse, len(pins))
+	for i, pin := range pins {
+		pinResponses[i] = newPinnedMessageResponse(&pin)
+	}
+
+	handleSuccess(ctx, pinResponses)
+}
+
+type getMentionsRequest struct {
+	Before string `form:"before" binding:"omitempty" example:"MjAyNS0wMS0wMVQwMDowMDowMFp8M2U0"`
+	Limit  uint64 `form:"limit" binding:"omitempty,min=1,max=100" example:"50"`
+	Unread bool   `form:"unread" example:"true"`
+}
+
+// GetMentions godoc
+//
+//	@Summary		List my mentions
+//	@Description	Get a page of the messages mentioning the current user, newest first. A mention is read once the message
+//	@Description	is marked as read; pass next_cursor as before to load older mentions.
+//	@Tags			Messages
+//	@Accept			json
+//	@Produce		json
+//	@Param			before	query		string			false	"Return mentions older than this cursor"
+//	@Param			limit	query		int				false	"Page size"	minimum(1)	maximum(100)	default(50)
+//	@Param			unread	query		bool			false	"Only return unread mentions"
+//	@Success		200		{object}	cursorMeta		"Mentions displayed"
+//	@Failure		400		{object}	errorResponse	"Validation error"
+//	@Failure		401		{object}	errorResponse	"Unauthorized error"
+//	@Failure		500		{object}	errorResponse	"Internal server error"
+//	@Router			/mentions [get]
+func (handler *MessageHandler) GetMentions(ctx *gin.Context) {
+	var req getMentionsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	page := domain.MentionPage{Limit: int(req.Limit), UnreadOnly: req.Unread}
+	if req.Before != "" {
+		page.Before, err = decodeMessageCursor(req.Before)
+		if err != nil {
+			handleError(ctx, err)
+			return
+		}
+	}
+
+	mentions, hasMore, err := handler.service.GetMentions(ctx.Request.Context(), userID.String(), page)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	mentionResponses := make([]mentionResponse, len(mentions))
+	for i, mention := range mentions {
+		mentionResponses[i] = newMentionResponse(&mention)
+	}
+
+	// the feed is ordered by mention time, so the cursor is built from the mention rather than its message
+	var nextCursor string
+	if len(mentions) > 0 {
+		last := mentions[len(mentions)-1]
+		nextCursor = encodeMessageCursor(&domain.Message{ID: last.MessageID, CreatedAt: last.CreatedAt})
+	}
+
+	meta := newCursorMeta(uint64(len(mentions)), hasMore, "", nextCursor)
+	rsp := toMap(meta, mentionResponses, "mentions")
+
+	handleSuccess(ctx, rsp)
+}
+
+type searchMessagesRequest struct {
+	Query         string     `form:"q" binding:"required,max=256" example:"station tomorrow"`
+	ChatID        string     `form:"chat_id" binding:"omitempty,uuid" example:"a7c8e3b1-5f0d-4d1e-9a5c-2b7f3e6d8c91"`
+	SenderID      string     `form:"sender_id" binding:"omitempty,uuid" example:"3342a227-1f2d-4422-a718-435c6a115f62"`
+	From          *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00" example:"2025-01-01T00:00:00Z"`
+	To            *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00" example:"2025-02-01T00:00:00Z"`
+	HasAttachment bool       `form:"has_attachment" example:"false"`
+	After         string     `form:"after" binding:"omitempty" example:"MC4wNjA3OTI3fDIwMjUtMDEtMDFUMDA6MDA6MDBafDNlNA"`
+	Limit         uint64     `form:"limit" binding:"omitempty,min=1,max=100" example:"20"`
+}
+
+// SearchMessages godoc
+//
+//	@Summary		Search messages
+//	@Description	Full-text search over the messages of the chats the current user participates in, best matches first.
+//	@Description	The query accepts quoted phrases, OR and -word; pass next_cursor as after to load the following results.
+//	@Tags			Messages
+//	@Accept			json
+//	@Produce		json
+//	@Param			q				query		string			true	"Search query"
+//	@Param			chat_id			query		string			false	"Only search this chat (UUID)"
+//	@Param			sender_id		query		string			false	"Only search messages of this user (UUID)"
+//	@Param			from			query		string			false	"Only messages sent at or after this time (RFC 3339)"
+//	@Param			to				query		string			false	"Only messages sent before this time (RFC 3339)"
+//	@Param			has_attachment	query		bool			false	"Only messages with attachments"
+//	@Param			after			query		string			false	"Return results after this cursor"
+//	@Param			limit			query		int				false	"Page size"	minimum(1)	maximum(100)	default(20)
+//	@Success		200				{object}	cursorMeta		"Search results displayed"
+//	@Failure		400				{object}	errorResponse	"Validation error"
+//	@Failure		401				{object}	errorResponse	"Unauthorized error"
+//	@Failure		500				{object}	errorResponse	"Internal server error"
+//	@Router			/messages/search [get]
+func (handler *MessageHandler) SearchMessages(ctx *gin.Context) {
+	var req searchMessagesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	search := domain.MessageSearch{
+		Query:         req.Query,
+		From:          req.From,
+		To:            req.To,
+		HasAttachment: req.HasAttachment,
+		Limit:         int(req.Limit),
+	}
+	if req.ChatID != "" {
+		chatID := uuid.MustParse(req.ChatID)
+		search.ChatID = &chatID
+	}
+	if req.SenderID != "" {
+		senderID := uuid.MustParse(req.SenderID)
+		search.SenderID = &senderID
+	}
+	if req.After != "" {
+		search.After, err = decodeSearchCursor(req.After)
+		if err != nil {
+			handleError(ctx, err)
+			return
+		}
+	}
+
+	results, hasMore, err := handler.service.SearchMessages(ctx.Request.Context(), userID.String(), search)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	resultResponses := make([]searchResultResponse, len(results))
+	for i, result := range results {
+		resultResponses[i] = newSearchResultResponse(&result)
+	}
+
+	var nextCursor string
+	if len(results) > 0 {
+		nextCursor = encodeSearchCursor(&results[len(results)-1])
+	}
+
+	meta := newCursorMeta(uint64(len(results)), hasMore, "", nextCursor)
+	rsp := toMap(meta, resultResponses, "results")
+
+	handleSuccess(ctx, rsp)
+}
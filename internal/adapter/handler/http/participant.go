@@ -0,0 +1,275 @@
+package httphandler
+
+import (
+	"github.com/HellEaglee/Golang-Chat/internal/core/port"
+	"github.com/HellEaglee/Golang-Chat/internal/core/util"
+	"github.com/gin-gonic/gin"
+)
+
+type ParticipantHandler struct {
+	service port.ChatService
+}
+
+func NewParticipantHandler(service port.ChatService) *ParticipantHandler {
+	return &ParticipantHandler{service: service}
+}
+
+type chatParticipantRequest struct {
+	ChatID string `uri:"id" binding:"required,uuid"`
+	UserID string `uri:"userID" binding:"required,uuid"`
+}
+
+// GetParticipants godoc
+//
+//	@Summary		List chat participants
+//	@Description	Get the active participants of a chat the current user participates in
+//	@Tags			Participants
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string			true	"Chat ID (UUID)"
+//	@Success		200	{object}	meta			"Participants displayed"
+//	@Failure		400	{object}	errorResponse	"Validation error"
+//	@Failure		401	{object}	errorResponse	"Unauthorized error"
+//	@Failure		403	{object}	errorResponse	"Forbidden error"
+//	@Failure		500	{object}	errorResponse	"Internal server error"
+//	@Router			/chats/{id}/participants [get]
+func (handler *ParticipantHandler) GetParticipants(ctx *gin.Context) {
+	var req getChatRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	if err := checkParticipant(ctx, handler.service, req.ID, userID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	participants, err := handler.service.GetChatParticipantsByChatID(ctx.Request.Context(), req.ID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	participantResponses := make([]participantResponse, len(participants))
+	for i, participant := range participants {
+		participantResponses[i] = newParticipantResponse(&participant)
+	}
+
+	total := uint64(len(participants))
+	meta := newMeta(total, total, 0)
+	rsp := toMap(meta, participantResponses, "participants")
+
+	handleSuccess(ctx, rsp)
+}
+
+type addParticipantRequest struct {
+	UserID string `json:"user_id" binding:"required,uuid" example:"3342a227-1f2d-4422-a718-435c6a115f62"`
+}
+
+// AddParticipant godoc
+//
+//	@Summary		Add a participant
+//	@Description	Add a user to a group chat as a member. Only admins and moderators can add participants.
+//	@Tags			Participants
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string					true	"Chat ID (UUID)"
+//	@Param			participant	body		addParticipantRequest	true	"Add participant request"
+//	@Success		200			{object}	participantResponse		"Participant added"
+//	@Failure		400			{object}	errorResponse			"Validation error"
+//	@Failure		401			{object}	errorResponse			"Unauthorized error"
+//	@Failure		403			{object}	errorResponse			"Forbidden error"
+//	@Failure		404			{object}	errorResponse			"Data not found error"
+//	@Failure		409			{object}	errorResponse			"Data conflict error"
+//	@Failure		500			{object}	errorResponse			"Internal server error"
+//	@Router			/chats/{id}/participants [post]
+func (handler *ParticipantHandler) AddParticipant(ctx *gin.Context) {
+	var uri getChatRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	var req addParticipantRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	participant, err := handler.service.AddChatParticipant(ctx.Request.Context(), userID.String(), uri.ID, req.UserID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	rsp := newParticipantResponse(participant)
+	handleSuccess(ctx, rsp)
+}
+
+// RemoveParticipant godoc
+//
+//	@Summary		Remove a participant
+//	@Description	Remove a participant from a chat. Admins can remove anyone, moderators can remove members. Direct chats cannot be left.
+//	@Tags			Participants
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string			true	"Chat ID (UUID)"
+//	@Param			userID	path		string			true	"User ID (UUID)"
+//	@Success		200		{object}	response		"Participant removed"
+//	@Failure		400		{object}	errorResponse	"Validation error"
+//	@Failure		401		{object}	errorResponse	"Unauthorized error"
+//	@Failure		403		{object}	errorResponse	"Forbidden error"
+//	@Failure		404		{object}	errorResponse	"Data not found error"
+//	@Failure		409		{object}	errorResponse	"Data conflict error"
+//	@Failure		500		{object}	errorResponse	"Internal server error"
+//	@Router			/chats/{id}/participants/{userID} [delete]
+func (handler *ParticipantHandler) RemoveParticipant(ctx *gin.Context) {
+	var req chatParticipantRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	err = handler.service.RemoveChatParticipant(ctx.Request.Context(), userID.String(), req.ChatID, req.UserID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	handleSuccess(ctx, nil)
+}
+
+// LeaveChat godoc
+//
+//	@Summary		Leave a chat
+//	@Description	Leave a chat. The last admin has to promote someone else before leaving.
+//	@Tags			Participants
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string			true	"Chat ID (UUID)"
+//	@Success		200	{object}	response		"Chat left"
+//	@Failure		400	{object}	errorResponse	"Validation error"
+//	@Failure		401	{object}	errorResponse	"Unauthorized error"
+//	@Failure		404	{object}	errorResponse	"Data not found error"
+//	@Failure		409	{object}	errorResponse	"Data conflict error"
+//	@Failure		500	{object}	errorResponse	"Internal server error"
+//	@Router			/chats/{id}/leave [post]
+func (handler *ParticipantHandler) LeaveChat(ctx *gin.Context) {
+	var req getChatRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	err = handler.service.LeaveChat(ctx.Request.Context(), req.ID, userID.String())
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	handleSuccess(ctx, nil)
+}
+
+// PromoteParticipant godoc
+//
+//	@Summary		Promote a participant
+//	@Description	Raise the role of a participant by one step (member to moderator, moderator to admin). Admins only.
+//	@Tags			Participants
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"Chat ID (UUID)"
+//	@Param			userID	path		string				true	"User ID (UUID)"
+//	@Success		200		{object}	participantResponse	"Participant promoted"
+//	@Failure		400		{object}	errorResponse		"Validation error"
+//	@Failure		401		{object}	errorResponse		"Unauthorized error"
+//	@Failure		403		{object}	errorResponse		"Forbidden error"
+//	@Failure		404		{object}	errorResponse		"Data not found error"
+//	@Failure		500		{object}	errorResponse		"Internal server error"
+//	@Router			/chats/{id}/participants/{userID}/promote [post]
+func (handler *ParticipantHandler) PromoteParticipant(ctx *gin.Context) {
+	var req chatParticipantRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	participant, err := handler.service.PromoteChatParticipant(ctx.Request.Context(), userID.String(), req.ChatID, req.UserID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	rsp := newParticipantResponse(participant)
+	handleSuccess(ctx, rsp)
+}
+
+// DemoteParticipant godoc
+//
+//	@Summary		Demote a participant
+//	@Description	Lower the role of a participant by one step (admin to moderator, moderator to member). Admins only.
+//	@Tags			Participants
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"Chat ID (UUID)"
+//	@Param			userID	path		string				true	"User ID (UUID)"
+//	@Success		200		{object}	participantResponse	"Participant demoted"
+//	@Failure		400		{object}	errorResponse		"Validation error"
+//	@Failure		401		{object}	errorResponse		"Unauthorized error"
+//	@Failure		403		{object}	errorResponse		"Forbidden error"
+//	@Failure		404		{object}	errorResponse		"Data not found error"
+//	@Failure		409		{object}	errorResponse		"Data conflict error"
+//	@Failure		500		{object}	errorResponse		"Internal server error"
+//	@Router			/chats/{id}/participants/{userID}/demote [post]
+func (handler *ParticipantHandler) DemoteParticipant(ctx *gin.Context) {
+	var req chatParticipantRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	participant, err := handler.service.DemoteChatParticipant(ctx.Request.Context(), userID.String(), req.ChatID, req.UserID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	rsp := newParticipantResponse(participant)
+	handleSuccess(ctx, rsp)
+}
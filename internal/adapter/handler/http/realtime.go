@@ -0,0 +1,349 @@
+package httphandler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/HellEaglee/Golang-Chat/internal/adapter/config"
+	"github.com/HellEaglee/Golang-Chat/internal/adapter/realtime"
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/HellEaglee/Golang-Chat/internal/core/port"
+	"github.com/HellEaglee/Golang-Chat/internal/core/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// Frames clients may send over the WebSocket
+const (
+	clientTypingStart    = "typing.start"
+	clientTypingStop     = "typing.stop"
+	clientPresenceAway   = "presence.away"
+	clientPresenceActive = "presence.active"
+	clientMessageAck     = "message.delivered"
+)
+
+type RealtimeHandler struct {
+	hub             *realtime.Hub
+	chatService     port.ChatService
+	messageService  port.MessageService
+	typingService   port.TypingService
+	presenceService port.PresenceService
+	subscriber      port.EventSubscriber
+	upgrader        websocket.Upgrader
+}
+
+func NewRealtimeHandler(config *config.HTTP, hub *realtime.Hub, chatService port.ChatService, messageService port.MessageService, typingService port.TypingService, presenceService port.PresenceService, subscriber port.EventSubscriber) *RealtimeHandler {
+	allowedOrigins := strings.Split(config.AllowedOrigins, ",")
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			return origin == "" || slices.Contains(allowedOrigins, origin)
+		},
+	}
+	return &RealtimeHandler{
+		hub:             hub,
+		chatService:     chatService,
+		messageService:  messageService,
+		typingService:   typingService,
+		presenceService: presenceService,
+		subscriber:      subscriber,
+		upgrader:        upgrader,
+	}
+}
+
+// Run forwards the events of the bus to the connections of this instance until ctx is done
+func (handler *RealtimeHandler) Run(ctx context.Context) error {
+	events, err := handler.subscriber.Subscribe(ctx)
+	if err != nil {
+		return err
+	}
+
+	for event := range events {
+		handler.forward(&event)
+	}
+	return ctx.Err()
+}
+
+// forward renders a domain event the way the HTTP API renders the same data and keeps the chat subscriptions in step with membership
+func (handler *RealtimeHandler) forward(event *domain.Event) {
+	frame := realtime.Event{ID: event.ID, Type: string(event.Type), ChatID: event.ChatID}
+
+	switch event.Type {
+	case domain.EventTypingStarted, domain.EventTypingStopped:
+		frame.Data = gin.H{"user_id": event.ActorID}
+		handler.hub.Signal(frame, event.ActorID)
+		return
+	case domain.EventPresenceChanged:
+		frame.Data = newPresenceResponse(event.Presence)
+		handler.hub.SignalUsers(frame, event.Recipients)
+		return
+	case domain.EventDraftUpdated:
+		// drafts are private, only the sessions of their author hear about them; a cleared draft comes as null
+		frame.Data = gin.H{"draft": newDraftResponse(event.Draft)}
+		handler.hub.SignalUsers(frame, []uuid.UUID{event.ActorID})
+		return
+	case domain.EventMessageCreated, domain.EventMessageUpdated:
+		frame.Data = newMessageResponse(event.Message)
+	case domain.EventMessageDeleted:
+		frame.Data = gin.H{"id": event.Message.ID}
+	case domain.EventMessageDelivered, domain.EventMessageRead:
+		frame.Data = gin.H{"user_id": event.ActorID, "message_id": event.Message.ID}
+	case domain.EventReactionAdded, domain.EventReactionRemoved:
+		frame.Data = gin.H{"user_id": event.Reaction.UserID, "message_id": event.Reaction.MessageID, "emoji": event.Reaction.Emoji}
+	case domain.EventMessagePinned:
+		frame.Data = newPinnedMessageResponse(event.Pin)
+	case domain.EventMessageUnpinned:
+		frame.Data = gin.H{"user_id": event.ActorID, "message_id": event.Message.ID}
+	case domain.EventMessagePreviewed:
+		frame.Data = gin.H{"message_id": event.Message.ID, "link_previews": newLinkPreviewResponses(event.Message.Text, event.Message.Entities, event.Message.LinkPreviews)}
+	case domain.EventAttachmentUpdated:
+		frame.Data = newAttachmentResponse(event.Attachment)
+	case domain.EventChatCreated:
+		for _, participant := range event.Chat.Participants {
+			handler.hub.Subscribe(participant.UserID, event.ChatID)
+		}
+		frame.Data = newChatResponse(event.Chat)
+	case domain.EventChatUpdated:
+		frame.Data = newChatResponse(event.Chat)
+	case domain.EventParticipantJoined:
+		handler.hub.Subscribe(event.Participant.UserID, event.ChatID)
+		frame.Data = newParticipantResponse(event.Participant)
+	case domain.EventParticipantUpdated:
+		frame.Data = newParticipantResponse(event.Participant)
+	case domain.EventParticipantLeft:
+		frame.Data = gin.H{"user_id": event.Participant.UserID}
+	}
+
+	handler.hub.Publish(frame)
+
+	switch event.Type {
+	case domain.EventParticipantLeft:
+		handler.hub.Unsubscribe(event.Participant.UserID, event.ChatID)
+	case domain.EventChatDeleted:
+		handler.hub.UnsubscribeAll(event.ChatID)
+	}
+}
+
+// chatIDsOf is a helper function to collect the chats the user is subscribed to when connecting
+func (handler *RealtimeHandler) chatIDsOf(ctx *gin.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	chats, err := handler.chatService.GetChatsByUserID(ctx.Request.Context(), userID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	chatIDs := make([]uuid.UUID, len(chats))
+	for i, chat := range chats {
+		chatIDs[i] = chat.ID
+	}
+	return chatIDs, nil
+}
+
+// lastEventID is a helper function to read the id of the last event a reconnecting client has seen,
+// from the Last-Event-ID header sent by EventSource or the last_event_id query parameter
+func lastEventID(ctx *gin.Context) uint64 {
+	value := ctx.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = ctx.Query("last_event_id")
+	}
+
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// connectPresence is a helper function to mark the user online for the lifetime of a realtime connection;
+// the returned function marks the connection gone
+func (handler *RealtimeHandler) connectPresence(ctx context.Context, userID uuid.UUID) (string, func()) {
+	connectionID, err := handler.presenceService.Connect(ctx, userID.String())
+	if err != nil {
+		slog.Error("Error updating presence", "user_id", userID, "error", err)
+	}
+
+	return connectionID, func() {
+		// the request context is usually cancelled by the time the connection is gone
+		err := handler.presenceService.Disconnect(context.WithoutCancel(ctx), userID.String(), connectionID)
+		if err != nil {
+			slog.Error("Error updating presence", "user_id", userID, "error", err)
+		}
+	}
+}
+
+// handleClientMessage acts on a frame sent over the WebSocket; frames it does not understand or is not allowed to act on are dropped
+func (handler *RealtimeHandler) handleClientMessage(ctx *gin.Context, userID uuid.UUID, connectionID string, message realtime.ClientMessage) {
+	var err error
+	switch message.Type {
+	case clientMessageAck:
+		err = handler.acknowledgeDelivery(ctx, userID, message)
+	case clientTypingStart:
+		err = handler.typingService.StartTyping(ctx.Request.Context(), message.ChatID.String(), userID.String())
+	case clientTypingStop:
+		err = handler.typingService.StopTyping(ctx.Request.Context(), message.ChatID.String(), userID.String())
+	case clientPresenceAway, clientPresenceActive:
+		err = handler.presenceService.SetAway(ctx.Request.Context(), userID.String(), connectionID, message.Type == clientPresenceAway)
+	default:
+		return
+	}
+	if err != nil && err != util.ErrForbidden && err != util.ErrDataNotFound {
+		slog.Error("Error handling realtime message", "type", message.Type, "user_id", userID, "error", err)
+	}
+}
+
+// acknowledgeDelivery marks the message from a message.delivered frame, and everything before it, as delivered
+func (handler *RealtimeHandler) acknowledgeDelivery(ctx *gin.Context, userID uuid.UUID, ack realtime.ClientMessage) error {
+	if err := checkParticipant(ctx, handler.chatService, ack.ChatID.String(), userID); err != nil {
+		return err
+	}
+
+	message, err := handler.messageService.GetMessage(ctx.Request.Context(), ack.MessageID.String())
+	if err != nil {
+		return err
+	}
+	if message.ChatID != ack.ChatID {
+		return util.ErrDataNotFound
+	}
+
+	return handler.messageService.MarkDelivered(ctx.Request.Context(), message, userID.String())
+}
+
+// ServeWS godoc
+//
+//	@Summary		Realtime WebSocket
+//	@Description	Upgrade to a WebSocket that receives JSON frames ({id, type, chat_id, data}) for every chat of the current user:
+//	@Description	message.created, message.updated, message.deleted, reaction.added, reaction.removed,
+//	@Description	chat.created, chat.updated, chat.deleted,
+//	@Description	participant.joined, participant.left and participant.updated, plus typing.started and typing.stopped
+//	@Description	signals, which have no id and are not replayed.
+//	@Description	Clients may send {"type": "typing.start" | "typing.stop", "chat_id": "..."} frames; a started indicator
+//	@Description	expires after a few seconds unless it is sent again. {"type": "presence.away" | "presence.active"} frames
+//	@Description	report the device idle or back; presence.changed signals reach everyone sharing a chat with the user.
+//	@Description	{"type": "message.delivered", "chat_id": "...", "message_id": "..."} acknowledges receipt of a message
+//	@Description	and everything before it; senders get message.delivered and message.read events.
+//	@Description	The server pings every 54 seconds and drops connections that stop answering or fall behind.
+//	@Description	Pass the id of the last received frame as last_event_id to resume after a reconnect.
+//	@Tags			Realtime
+//	@Param			last_event_id	query	int	false	"Resume after this event id"
+//	@Success		101				"Switching protocols"
+//	@Failure		401				{object}	errorResponse	"Unauthorized error"
+//	@Failure		500				{object}	errorResponse	"Internal server error"
+//	@Router			/ws [get]
+func (handler *RealtimeHandler) ServeWS(ctx *gin.Context) {
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	chatIDs, err := handler.chatIDsOf(ctx, userID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	conn, err := handler.upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		// the upgrader has already replied to the client
+		return
+	}
+
+	connectionID, disconnect := handler.connectPresence(ctx.Request.Context(), userID)
+	defer disconnect()
+
+	realtime.ServeWebSocket(handler.hub, conn, userID, chatIDs, lastEventID(ctx), func(message realtime.ClientMessage) {
+		handler.handleClientMessage(ctx, userID, connectionID, message)
+	})
+}
+
+// ServeSSE godoc
+//
+//	@Summary		Realtime event stream
+//	@Description	Server-Sent Events fallback for clients that cannot open a WebSocket. Every event carries the same JSON
+//	@Description	as the WebSocket frames in its data field and the event id in its id field. Reconnecting browsers send
+//	@Description	Last-Event-ID and receive what they missed; a stream.reset event means the gap is too old and the state
+//	@Description	has to be reloaded over HTTP.
+//	@Tags			Realtime
+//	@Produce		text/event-stream
+//	@Param			Last-Event-ID	header		int				false	"Resume after this event id"
+//	@Param			last_event_id	query		int				false	"Resume after this event id"
+//	@Success		200				{string}	string			"Event stream"
+//	@Failure		401				{object}	errorResponse	"Unauthorized error"
+//	@Failure		500				{object}	errorResponse	"Internal server error"
+//	@Router			/events [get]
+func (handler *RealtimeHandler) ServeSSE(ctx *gin.Context) {
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	chatIDs, err := handler.chatIDsOf(ctx, userID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	_, disconnect := handler.connectPresence(ctx.Request.Context(), userID)
+	defer disconnect()
+
+	realtime.ServeEventStream(ctx.Request.Context(), handler.hub, ctx.Writer, userID, chatIDs, lastEventID(ctx))
+}
+
+type setTypingRequest struct {
+	State string `json:"state" binding:"required,oneof=start stop" example:"start"`
+}
+
+// SetTyping godoc
+//
+//	@Summary		Send a typing indicator
+//	@Description	Tell the other participants of the chat that the current user started or stopped typing.
+//	@Description	A started indicator expires after a few seconds unless it is sent again. Nothing is stored.
+//	@Tags			Realtime
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"Chat ID (UUID)"
+//	@Param			typing	body		setTypingRequest	true	"Typing state"
+//	@Success		200		{object}	response			"Typing indicator sent"
+//	@Failure		400		{object}	errorResponse		"Validation error"
+//	@Failure		401		{object}	errorResponse		"Unauthorized error"
+//	@Failure		403		{object}	errorResponse		"Forbidden error"
+//	@Failure		500		{object}	errorResponse		"Internal server error"
+//	@Router			/chats/{id}/typing [post]
+func (handler *RealtimeHandler) SetTyping(ctx *gin.Context) {
+	var uri getChatRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	var req setTypingRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	if req.State == "start" {
+		err = handler.typingService.StartTyping(ctx.Request.Context(), uri.ID, userID.String())
+	} else {
+		err = handler.typingService.StopTyping(ctx.Request.Context(), uri.ID, userID.String())
+	}
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	handleSuccess(ctx, nil)
+}
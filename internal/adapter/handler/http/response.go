@@ -1,9 +1,14 @@
 package httphandler
 
 import (
+	"cmp"
 	"errors"
+	"math"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
+	"unicode/utf16"
 
 	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
 	"github.com/HellEaglee/Golang-Chat/internal/core/util"
@@ -21,10 +26,25 @@ var errorStatusMap = map[error]int{
 	util.ErrRefreshTokenCreation: http.StatusInternalServerError,
 
 	// Client codes - 4XX
-	util.ErrSessionRevoked:  http.StatusGone,
-	util.ErrConflictingData: http.StatusConflict,
-	util.ErrDataNotFound:    http.StatusNotFound,
-	util.ErrNoUpdatedData:   http.StatusBadRequest,
+	util.ErrSessionRevoked:             http.StatusGone,
+	util.ErrConflictingData:            http.StatusConflict,
+	util.ErrLastChatAdmin:              http.StatusConflict,
+	util.ErrPinLimitReached:            http.StatusConflict,
+	util.ErrScheduledMessageNotPending: http.StatusConflict,
+	util.ErrDataNotFound:               http.StatusNotFound,
+	util.ErrNoUpdatedData:              http.StatusBadRequest,
+	util.ErrInvalidCursor:              http.StatusBadRequest,
+	util.ErrInvalidDirectChat:          http.StatusBadRequest,
+	util.ErrInvalidReaction:            http.StatusBadRequest,
+	util.ErrInvalidReply:               http.StatusBadRequest,
+	util.ErrInvalidSendTime:            http.StatusBadRequest,
+	util.ErrInvalidMessageTTL:          http.StatusBadRequest,
+	util.ErrInvalidForward:             http.StatusBadRequest,
+	util.ErrInvalidFormatting:          http.StatusBadRequest,
+	util.ErrMessageTooLong:             http.StatusBadRequest,
+	util.ErrInvalidAttachment:          http.StatusBadRequest,
+	util.ErrFileTooLarge:               http.StatusRequestEntityTooLarge,
+	util.ErrUnsupportedFileType:        http.StatusUnsupportedMediaType,
 
 	// Authentication & Authorization code - 401/403
 	util.ErrInvalidCredentials:         http.StatusUnauthorized,
@@ -38,6 +58,7 @@ var errorStatusMap = map[error]int{
 	util.ErrExpiredRefreshToken:        http.StatusUnauthorized,
 	util.ErrInvalidSession:             http.StatusUnauthorized,
 	util.ErrForbidden:                  http.StatusForbidden,
+	util.ErrEditWindowExpired:          http.StatusForbidden,
 }
 
 type response struct {
@@ -68,6 +89,36 @@ func newMeta(total, limit, skip uint64) meta {
 	}
 }
 
+type cursorMeta struct {
+	Limit      uint64 `json:"limit" example:"50"`
+	HasMore    bool   `json:"has_more" example:"true"`
+	PrevCursor string `json:"prev_cursor,omitempty" example:"MjAyNS0wMS0wMVQwMDowMDowMFp8M2U0"`
+	NextCursor string `json:"next_cursor,omitempty" example:"MjAyNS0wMS0wMVQwMDowMDowMFp8M2U0"`
+}
+
+func newCursorMeta(limit uint64, hasMore bool, prevCursor, nextCursor string) cursorMeta {
+	return cursorMeta{
+		Limit:      limit,
+		HasMore:    hasMore,
+		PrevCursor: prevCursor,
+		NextCursor: nextCursor,
+	}
+}
+
+type seqMeta struct {
+	Limit   uint64 `json:"limit" example:"100"`
+	HasMore bool   `json:"has_more" example:"false"`
+	LastSeq int64  `json:"last_seq" example:"42"`
+}
+
+func newSeqMeta(limit uint64, hasMore bool, lastSeq int64) seqMeta {
+	return seqMeta{
+		Limit:   limit,
+		HasMore: hasMore,
+		LastSeq: lastSeq,
+	}
+}
+
 type authResponse struct {
 	Message string `json:"message"`
 }
@@ -77,11 +128,13 @@ func newAuthResponse(message string) authResponse {
 }
 
 type userResponse struct {
-	ID        uuid.UUID `json:"id" example:"3342a227-1f2d-4422-a718-435c6a115f62"`
-	Name      string    `json:"name" example:"John"`
-	Email     string    `json:"email" example:"john@gmail.com"`
-	CreatedAt time.Time `json:"created_at" example:"1970-01-01T00:00:00Z"`
-	UpdatedAt time.Time `json:"updated_at" example:"1970-01-01T00:00:00Z"`
+	ID         uuid.UUID  `json:"id" example:"3342a227-1f2d-4422-a718-435c6a115f62"`
+	Name       string     `json:"name" example:"John"`
+	Email      string     `json:"email" example:"john@gmail.com"`
+	Status     string     `json:"status" example:"online" enums:"online,away,offline"`
+	LastSeenAt *time.Time `json:"last_seen_at" example:"1970-01-01T00:00:00Z"`
+	CreatedAt  time.Time  `json:"created_at" example:"1970-01-01T00:00:00Z"`
+	UpdatedAt  time.Time  `json:"updated_at" example:"1970-01-01T00:00:00Z"`
 }
 
 type csrfResponse struct {
@@ -94,11 +147,437 @@ func newCSRFResponse(message string) csrfResponse {
 
 func newUserResponse(user *domain.User) userResponse {
 	return userResponse{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		ID:         user.ID,
+		Name:       user.Name,
+		Email:      user.Email,
+		Status:     user.Status,
+		LastSeenAt: user.LastSeenAt,
+		CreatedAt:  user.CreatedAt,
+		UpdatedAt:  user.UpdatedAt,
+	}
+}
+
+type presenceResponse struct {
+	UserID     uuid.UUID  `json:"user_id" example:"3342a227-1f2d-4422-a718-435c6a115f62"`
+	Status     string     `json:"status" example:"online" enums:"online,away,offline"`
+	LastSeenAt *time.Time `json:"last_seen_at" example:"1970-01-01T00:00:00Z"`
+}
+
+func newPresenceResponse(presence *domain.Presence) presenceResponse {
+	return presenceResponse{
+		UserID:     presence.UserID,
+		Status:     presence.Status,
+		LastSeenAt: presence.LastSeenAt,
+	}
+}
+
+type chatResponse struct {
+	ID            uuid.UUID `json:"id" example:"a7c8e3b1-5f0d-4d1e-9a5c-2b7f3e6d8c91"`
+	Name          *string   `json:"name" example:"Team chat"`
+	IsGroup       bool      `json:"is_group" example:"true"`
+	LastMessage   string    `json:"last_message" example:"Hello there"`
+	LastMessageAt time.Time `json:"last_message_at" example:"1970-01-01T00:00:00Z"`
+	LastSeq       int64     `json:"last_seq" example:"42"`
+	MessageTTL    int       `json:"message_ttl" example:"86400"`
+	CreatedAt     time.Time `json:"created_at" example:"1970-01-01T00:00:00Z"`
+	UpdatedAt     time.Time `json:"updated_at" example:"1970-01-01T00:00:00Z"`
+	// Draft is only filled in the chat list of the current user
+	Draft *draftResponse `json:"draft,omitempty"`
+}
+
+func newChatResponse(chat *domain.Chat) chatResponse {
+	return chatResponse{
+		ID:            chat.ID,
+		Name:          chat.Name,
+		IsGroup:       chat.IsGroup,
+		LastMessage:   chat.LastMessage,
+		LastMessageAt: chat.LastMessageAt,
+		LastSeq:       chat.LastSeq,
+		MessageTTL:    chat.MessageTTL,
+		CreatedAt:     chat.CreatedAt,
+		UpdatedAt:     chat.UpdatedAt,
+		Draft:         newDraftResponse(chat.Draft),
+	}
+}
+
+type draftResponse struct {
+	ChatID           uuid.UUID  `json:"chat_id" example:"a7c8e3b1-5f0d-4d1e-9a5c-2b7f3e6d8c91"`
+	Text             string     `json:"text" example:"I was about to say"`
+	ReplyToMessageID *uuid.UUID `json:"reply_to_message_id" example:"6b0f7d9e-2c3a-4f5b-8e1d-9a4c7b2e5f30"`
+	UpdatedAt        time.Time  `json:"updated_at" example:"1970-01-01T00:00:00Z"`
+}
+
+func newDraftResponse(draft *domain.ChatDraft) *draftResponse {
+	if draft == nil {
+		return nil
+	}
+	return &draftResponse{
+		ChatID:           draft.ChatID,
+		Text:             draft.Text,
+		ReplyToMessageID: draft.ReplyToMessageID,
+		UpdatedAt:        draft.UpdatedAt,
+	}
+}
+
+type participantResponse struct {
+	ChatID   uuid.UUID `json:"chat_id" example:"a7c8e3b1-5f0d-4d1e-9a5c-2b7f3e6d8c91"`
+	UserID   uuid.UUID `json:"user_id" example:"3342a227-1f2d-4422-a718-435c6a115f62"`
+	Role     string    `json:"role" example:"member" enums:"admin,moderator,member"`
+	JoinedAt time.Time `json:"joined_at" example:"1970-01-01T00:00:00Z"`
+}
+
+func newParticipantResponse(participant *domain.ChatParticipant) participantResponse {
+	return participantResponse{
+		ChatID:   participant.ChatID,
+		UserID:   participant.UserID,
+		Role:     participant.Role,
+		JoinedAt: participant.JoinedAt,
+	}
+}
+
+type messageResponse struct {
+	ID               uuid.UUID               `json:"id" example:"6b0f7d9e-2c3a-4f5b-8e1d-9a4c7b2e5f30"`
+	ChatID           uuid.UUID               `json:"chat_id" example:"a7c8e3b1-5f0d-4d1e-9a5c-2b7f3e6d8c91"`
+	Seq              int64                   `json:"seq" example:"42"`
+	UserID           uuid.UUID               `json:"user_id" example:"3342a227-1f2d-4422-a718-435c6a115f62"`
+	Kind             string                  `json:"kind" example:"user" enums:"user,system"`
+	Text             string                  `json:"text" example:"Hello there"`
+	Entities         []messageEntityResponse `json:"entities"`
+	IsEdited         bool                    `json:"is_edited" example:"false"`
+	IsDeleted        bool                    `json:"is_deleted" example:"false"`
+	ReplyToMessageID *uuid.UUID              `json:"reply_to_message_id" example:"6b0f7d9e-2c3a-4f5b-8e1d-9a4c7b2e5f30"`
+	ForwardedFrom    *forwardedFromResponse  `json:"forwarded_from"`
+	Reactions        []reactionResponse      `json:"reactions"`
+	Attachments      []attachmentResponse    `json:"attachments"`
+	LinkPreviews     []linkPreviewResponse   `json:"link_previews"`
+	ReplyCount       int                     `json:"reply_count" example:"3"`
+	LastReplyAt      *time.Time              `json:"last_reply_at" example:"1970-01-01T00:00:00Z"`
+	ExpiresAt        *time.Time              `json:"expires_at" example:"1970-01-02T00:00:00Z"`
+	CreatedAt        time.Time               `json:"created_at" example:"1970-01-01T00:00:00Z"`
+	UpdatedAt        time.Time               `json:"updated_at" example:"1970-01-01T00:00:00Z"`
+}
+
+// newMessageResponse blanks the text of deleted messages, which only show up as placeholders when replaying by sequence
+func newMessageResponse(message *domain.Message) messageResponse {
+	text, entities, attachments, previews := message.Text, message.Entities, message.Attachments, message.LinkPreviews
+	if message.DeletedAt.Valid {
+		text, entities, attachments, previews = "", nil, nil, nil
+	}
+
+	return messageResponse{
+		ID:               message.ID,
+		ChatID:           message.ChatID,
+		Seq:              message.Seq,
+		UserID:           message.UserID,
+		Kind:             message.Kind,
+		Text:             text,
+		Entities:         newMessageEntityResponses(entities),
+		IsEdited:         message.IsEdited,
+		IsDeleted:        message.DeletedAt.Valid,
+		ReplyToMessageID: message.ReplyToMessageID,
+		ForwardedFrom:    newForwardedFromResponse(message),
+		Reactions:        newReactionResponses(message.Reactions),
+		Attachments:      newAttachmentResponses(attachments),
+		LinkPreviews:     newLinkPreviewResponses(text, entities, previews),
+		ReplyCount:       message.Thread.ReplyCount,
+		LastReplyAt:      message.Thread.LastReplyAt,
+		ExpiresAt:        message.ExpiresAt,
+		CreatedAt:        message.CreatedAt,
+		UpdatedAt:        message.UpdatedAt,
+	}
+}
+
+// messageEntityResponse is a formatted span of the text, with offset and length in UTF-16 code units
+type messageEntityResponse struct {
+	Type     string `json:"type" example:"bold" enums:"bold,italic,code,pre,link,quote"`
+	Offset   int    `json:"offset" example:"0"`
+	Length   int    `json:"length" example:"5"`
+	URL      string `json:"url,omitempty" example:"https://example.com"`
+	Language string `json:"language,omitempty" example:"go"`
+}
+
+func newMessageEntityResponses(entities []domain.MessageEntity) []messageEntityResponse {
+	responses := make([]messageEntityResponse, len(entities))
+	for i, entity := range entities {
+		responses[i] = messageEntityResponse{
+			Type:     entity.Type,
+			Offset:   entity.Offset,
+			Length:   entity.Length,
+			URL:      entity.URL,
+			Language: entity.Language,
+		}
+	}
+	return responses
+}
+
+// renderedMessageResponse is the text of a message rendered in the requested format
+type renderedMessageResponse struct {
+	MessageID uuid.UUID `json:"message_id" example:"6b0f7d9e-2c3a-4f5b-8e1d-9a4c7b2e5f30"`
+	Format    string    `json:"format" example:"html" enums:"html,plain"`
+	Content   string    `json:"content" example:"<strong>Hello</strong> there"`
+}
+
+// forwardedFromResponse names the origin of a forwarded message; its ids are null once that origin is gone
+type forwardedFromResponse struct {
+	MessageID *uuid.UUID `json:"message_id" example:"6b0f7d9e-2c3a-4f5b-8e1d-9a4c7b2e5f30"`
+	ChatID    *uuid.UUID `json:"chat_id" example:"a7c8e3b1-5f0d-4d1e-9a5c-2b7f3e6d8c91"`
+	UserID    *uuid.UUID `json:"user_id" example:"3342a227-1f2d-4422-a718-435c6a115f62"`
+}
+
+func newForwardedFromResponse(message *domain.Message) *forwardedFromResponse {
+	if message.ForwardedFromMessageID == nil && message.ForwardedFromChatID == nil && message.ForwardedFromUserID == nil {
+		return nil
+	}
+	return &forwardedFromResponse{
+		MessageID: message.ForwardedFromMessageID,
+		ChatID:    message.ForwardedFromChatID,
+		UserID:    message.ForwardedFromUserID,
+	}
+}
+
+type attachmentResponse struct {
+	ID              uuid.UUID           `json:"id" example:"0f8e7d6c-5b4a-4392-8170-6f5e4d3c2b1a"`
+	MessageID       *uuid.UUID          `json:"message_id" example:"6b0f7d9e-2c3a-4f5b-8e1d-9a4c7b2e5f30"`
+	FileName        string              `json:"file_name" example:"holiday.jpg"`
+	ContentType     string              `json:"content_type" example:"image/jpeg"`
+	Size            int64               `json:"size" example:"204800"`
+	Width           *int                `json:"width" example:"1920"`
+	Height          *int                `json:"height" example:"1080"`
+	ThumbnailStatus string              `json:"thumbnail_status" example:"ready" enums:"none,pending,processing,ready,failed"`
+	Thumbnails      []thumbnailResponse `json:"thumbnails"`
+	CreatedAt       time.Time           `json:"created_at" example:"1970-01-01T00:00:00Z"`
+}
+
+type thumbnailResponse struct {
+	Size        string `json:"size" example:"small" enums:"small,medium"`
+	Width       int    `json:"width" example:"160"`
+	Height      int    `json:"height" example:"90"`
+	ContentType string `json:"content_type" example:"image/jpeg"`
+}
+
+func newAttachmentResponse(attachment *domain.Attachment) attachmentResponse {
+	thumbnails := make([]thumbnailResponse, len(attachment.Thumbnails))
+	for i, thumbnail := range attachment.Thumbnails {
+		thumbnails[i] = thumbnailResponse{
+			Size:        thumbnail.Size,
+			Width:       thumbnail.Width,
+			Height:      thumbnail.Height,
+			ContentType: thumbnail.ContentType,
+		}
+	}
+
+	return attachmentResponse{
+		ID:              attachment.ID,
+		MessageID:       attachment.MessageID,
+		FileName:        attachment.FileName,
+		ContentType:     attachment.ContentType,
+		Size:            attachment.Size,
+		Width:           attachment.Width,
+		Height:          attachment.Height,
+		ThumbnailStatus: attachment.ThumbnailStatus,
+		Thumbnails:      thumbnails,
+		CreatedAt:       attachment.CreatedAt,
+	}
+}
+
+func newAttachmentResponses(attachments []domain.Attachment) []attachmentResponse {
+	responses := make([]attachmentResponse, len(attachments))
+	for i, attachment := range attachments {
+		responses[i] = newAttachmentResponse(&attachment)
+	}
+	return responses
+}
+
+type linkPreviewResponse struct {
+	URL         string `json:"url" example:"https://go.dev/blog/"`
+	Title       string `json:"title" example:"The Go Blog"`
+	Description string `json:"description" example:"The Go Programming Language"`
+	ImageURL    string `json:"image_url" example:"https://go.dev/images/go-logo-white.svg"`
+	SiteName    string `json:"site_name" example:"go.dev"`
+}
+
+// newLinkPreviewResponses only keeps the previews that were fetched, in the order their links appear in the text,
+// written out or behind a link entity
+func newLinkPreviewResponses(text string, entities []domain.MessageEntity, previews []domain.LinkPreview) []linkPreviewResponse {
+	ready := slices.DeleteFunc(slices.Clone(previews), func(preview domain.LinkPreview) bool {
+		return preview.Status != domain.LinkPreviewReady
+	})
+	slices.SortStableFunc(ready, func(a, b domain.LinkPreview) int {
+		return cmp.Compare(linkPosition(text, entities, a.URL), linkPosition(text, entities, b.URL))
+	})
+
+	responses := make([]linkPreviewResponse, len(ready))
+	for i, preview := range ready {
+		responses[i] = linkPreviewResponse{
+			URL:         preview.URL,
+			Title:       preview.Title,
+			Description: preview.Description,
+			ImageURL:    preview.ImageURL,
+			SiteName:    preview.SiteName,
+		}
+	}
+	return responses
+}
+
+// linkPosition returns where the link first appears in UTF-16 code units like entity offsets, or past the end of
+// the text if it cannot be found
+func linkPosition(text string, entities []domain.MessageEntity, url string) int {
+	position := math.MaxInt
+	if i := strings.Index(text, url); i >= 0 {
+		position = len(utf16.Encode([]rune(text[:i])))
+	}
+	for _, entity := range entities {
+		if entity.Type == domain.EntityLink && entity.URL == url && entity.Offset < position {
+			return entity.Offset
+		}
+	}
+	return position
+}
+
+type messageEditResponse struct {
+	ID        uuid.UUID               `json:"id" example:"c1d2e3f4-a5b6-4c7d-8e9f-0a1b2c3d4e5f"`
+	Text      string                  `json:"text" example:"Hello thre"`
+	Entities  []messageEntityResponse `json:"entities"`
+	WrittenAt time.Time               `json:"written_at" example:"1970-01-01T00:00:00Z"`
+	EditedAt  time.Time               `json:"edited_at" example:"1970-01-01T00:00:00Z"`
+}
+
+func newMessageEditResponse(edit *domain.MessageEdit) messageEditResponse {
+	return messageEditResponse{
+		ID:        edit.ID,
+		Text:      edit.Text,
+		Entities:  newMessageEntityResponses(edit.Entities),
+		WrittenAt: edit.WrittenAt,
+		EditedAt:  edit.EditedAt,
+	}
+}
+
+type reactionResponse struct {
+	Emoji   string `json:"emoji" example:"👍"`
+	Count   int    `json:"count" example:"3"`
+	Reacted bool   `json:"reacted" example:"true"`
+}
+
+func newReactionResponses(counts []domain.ReactionCount) []reactionResponse {
+	reactions := make([]reactionResponse, len(counts))
+	for i, count := range counts {
+		reactions[i] = reactionResponse{
+			Emoji:   count.Emoji,
+			Count:   count.Count,
+			Reacted: count.Reacted,
+		}
+	}
+	return reactions
+}
+
+type pinnedMessageResponse struct {
+	Message  messageResponse `json:"message"`
+	PinnedBy *uuid.UUID      `json:"pinned_by" example:"3342a227-1f2d-4422-a718-435c6a115f62"`
+	PinnedAt time.Time       `json:"pinned_at" example:"1970-01-01T00:00:00Z"`
+}
+
+func newPinnedMessageResponse(pin *domain.PinnedMessage) pinnedMessageResponse {
+	return pinnedMessageResponse{
+		Message:  newMessageResponse(&pin.Message),
+		PinnedBy: pin.PinnedBy,
+		PinnedAt: pin.PinnedAt,
+	}
+}
+
+type mentionResponse struct {
+	Message   messageResponse `json:"message"`
+	Kind      string          `json:"kind" example:"user" enums:"user,all,here"`
+	IsRead    bool            `json:"is_read" example:"false"`
+	CreatedAt time.Time       `json:"created_at" example:"1970-01-01T00:00:00Z"`
+}
+
+func newMentionResponse(mention *domain.MessageMention) mentionResponse {
+	return mentionResponse{
+		Message:   newMessageResponse(&mention.Message),
+		Kind:      mention.Kind,
+		IsRead:    mention.IsRead,
+		CreatedAt: mention.CreatedAt,
+	}
+}
+
+type scheduledMessageResponse struct {
+	ID               uuid.UUID  `json:"id" example:"9d2f6c1e-4b7a-4e3d-8c5f-1a2b3c4d5e6f"`
+	ChatID           uuid.UUID  `json:"chat_id" example:"0f8e7d6c-5b4a-4392-8170-6f5e4d3c2b1a"`
+	Text             string     `json:"text" example:"Happy **birthday**!"`
+	Format           string     `json:"format" example:"markdown" enums:"plain,markdown"`
+	ReplyToMessageID *uuid.UUID `json:"reply_to_message_id" example:"6b0f7d9e-2c3a-4f5b-8e1d-9a4c7b2e5f30"`
+	SendAt           time.Time  `json:"send_at" example:"1970-01-01T00:00:00Z"`
+	Status           string     `json:"status" example:"pending" enums:"pending,sending,sent,failed,canceled"`
+	FailureReason    string     `json:"failure_reason,omitempty" example:"user is forbidden to access the resource"`
+	SentAt           *time.Time `json:"sent_at" example:"1970-01-01T00:00:00Z"`
+	CreatedAt        time.Time  `json:"created_at" example:"1970-01-01T00:00:00Z"`
+	UpdatedAt        time.Time  `json:"updated_at" example:"1970-01-01T00:00:00Z"`
+}
+
+func newScheduledMessageResponse(scheduled *domain.ScheduledMessage) scheduledMessageResponse {
+	return scheduledMessageResponse{
+		ID:               scheduled.ID,
+		ChatID:           scheduled.ChatID,
+		Text:             scheduled.Text,
+		Format:           scheduled.Format,
+		ReplyToMessageID: scheduled.ReplyToMessageID,
+		SendAt:           scheduled.SendAt,
+		Status:           scheduled.Status,
+		FailureReason:    scheduled.FailureReason,
+		SentAt:           scheduled.SentAt,
+		CreatedAt:        scheduled.CreatedAt,
+		UpdatedAt:        scheduled.UpdatedAt,
+	}
+}
+
+type searchResultResponse struct {
+	Message messageResponse `json:"message"`
+	Rank    float32         `json:"rank" example:"0.0607927"`
+	Snippet string          `json:"snippet" example:"see you at the <mark>station</mark> tomorrow"`
+}
+
+func newSearchResultResponse(result *domain.MessageSearchResult) searchResultResponse {
+	return searchResultResponse{
+		Message: newMessageResponse(&result.Message),
+		Rank:    result.Rank,
+		Snippet: result.Snippet,
+	}
+}
+
+type messageReceiptResponse struct {
+	UserID      uuid.UUID  `json:"user_id" example:"3342a227-1f2d-4422-a718-435c6a115f62"`
+	Status      string     `json:"status" example:"delivered" enums:"sent,delivered,read"`
+	DeliveredAt *time.Time `json:"delivered_at" example:"1970-01-01T00:00:00Z"`
+	ReadAt      *time.Time `json:"read_at" example:"1970-01-01T00:00:00Z"`
+}
+
+type messageStatusResponse struct {
+	MessageID      uuid.UUID                `json:"message_id" example:"6b0f7d9e-2c3a-4f5b-8e1d-9a4c7b2e5f30"`
+	Status         string                   `json:"status" example:"delivered" enums:"sent,delivered,read"`
+	Recipients     int                      `json:"recipients" example:"3"`
+	DeliveredCount int                      `json:"delivered_count" example:"3"`
+	ReadCount      int                      `json:"read_count" example:"1"`
+	Receipts       []messageReceiptResponse `json:"receipts"`
+}
+
+func newMessageStatusResponse(status *domain.MessageStatus) messageStatusResponse {
+	receipts := make([]messageReceiptResponse, len(status.Receipts))
+	for i, receipt := range status.Receipts {
+		receipts[i] = messageReceiptResponse{
+			UserID:      receipt.UserID,
+			Status:      receipt.Status,
+			DeliveredAt: receipt.DeliveredAt,
+			ReadAt:      receipt.ReadAt,
+		}
+	}
+
+	return messageStatusResponse{
+		MessageID:      status.MessageID,
+		Status:         status.Status,
+		Recipients:     len(status.Receipts),
+		DeliveredCount: status.DeliveredCount,
+		ReadCount:      status.ReadCount,
+		Receipts:       receipts,
 	}
 }
 
@@ -0,0 +1,32 @@
+package httphandler
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+)
+
+func TestLinkPreviewResponsesOrder(t *testing.T) {
+	// the emoji takes two UTF-16 code units, so the link behind "docs" starts at 3
+	text := "\U0001F600 docs, then https://two.example and more"
+	entities := []domain.MessageEntity{
+		{Type: domain.EntityBold, Offset: 0, Length: 2},
+		{Type: domain.EntityLink, Offset: 3, Length: 4, URL: "https://one.example"},
+	}
+	previews := []domain.LinkPreview{
+		{URL: "https://unknown.example", Status: domain.LinkPreviewReady},
+		{URL: "https://two.example", Status: domain.LinkPreviewReady},
+		{URL: "https://failed.example", Status: domain.LinkPreviewFailed},
+		{URL: "https://one.example", Status: domain.LinkPreviewReady},
+	}
+
+	var got []string
+	for _, preview := range newLinkPreviewResponses(text, entities, previews) {
+		got = append(got, preview.URL)
+	}
+	want := []string{"https://one.example", "https://two.example", "https://unknown.example"}
+	if !slices.Equal(got, want) {
+		t.Errorf("previews in order %v, want %v", got, want)
+	}
+}
@@ -19,6 +19,8 @@ type Router struct {
 
 func NewRouter(config *config.HTTP, tokenConfig *config.Token,
 	token port.TokenService, csrf port.CSRFService, authHandler AuthHandler, userHandler UserHandler,
+	chatHandler ChatHandler, messageHandler MessageHandler, participantHandler ParticipantHandler,
+	realtimeHandler RealtimeHandler, attachmentHandler AttachmentHandler, scheduledMessageHandler ScheduledMessageHandler,
 ) (*Router, error) {
 	if config.Env == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -53,6 +55,66 @@ func NewRouter(config *config.HTTP, tokenConfig *config.Token,
 			users.PUT("/:id", userHandler.UpdateUser)
 			users.DELETE("/:id", userHandler.DeleteUser)
 		}
+		chats := v1.Group("/chats")
+		chats.Use(authMiddleWare(token, csrf, tokenConfig))
+		{
+			chats.POST("/", chatHandler.CreateChat)
+			chats.GET("/", chatHandler.GetMyChats)
+			chats.GET("/:id", chatHandler.GetChat)
+			chats.PUT("/:id", chatHandler.UpdateChat)
+			chats.DELETE("/:id", chatHandler.DeleteChat)
+			chats.PUT("/:id/message-ttl", chatHandler.SetMessageTTL)
+			chats.PUT("/:id/draft", chatHandler.SaveDraft)
+
+			chats.GET("/:id/participants", participantHandler.GetParticipants)
+			chats.POST("/:id/participants", participantHandler.AddParticipant)
+			chats.DELETE("/:id/participants/:userID", participantHandler.RemoveParticipant)
+			chats.POST("/:id/participants/:userID/promote", participantHandler.PromoteParticipant)
+			chats.POST("/:id/participants/:userID/demote", participantHandler.DemoteParticipant)
+			chats.POST("/:id/leave", participantHandler.LeaveChat)
+
+			chats.POST("/:id/messages", messageHandler.SendMessage)
+			chats.GET("/:id/messages", messageHandler.GetMessages)
+			chats.GET("/:id/messages/sync", messageHandler.SyncMessages)
+			chats.POST("/:id/messages/forward", messageHandler.ForwardMessages)
+			chats.PUT("/:id/messages/:messageID", messageHandler.UpdateMessage)
+			chats.DELETE("/:id/messages/:messageID", messageHandler.DeleteMessage)
+			chats.POST("/:id/messages/:messageID/delivered", messageHandler.MarkDelivered)
+			chats.POST("/:id/messages/:messageID/read", messageHandler.MarkRead)
+			chats.GET("/:id/messages/:messageID/status", messageHandler.GetMessageStatus)
+			chats.GET("/:id/messages/:messageID/edits", messageHandler.GetMessageEdits)
+			chats.GET("/:id/messages/:messageID/render", messageHandler.RenderMessage)
+			chats.GET("/:id/messages/:messageID/thread", messageHandler.GetThread)
+			chats.GET("/:id/pins", messageHandler.GetPinnedMessages)
+			chats.POST("/:id/messages/:messageID/pin", messageHandler.PinMessage)
+			chats.DELETE("/:id/messages/:messageID/pin", messageHandler.UnpinMessage)
+			chats.POST("/:id/messages/:messageID/reactions", messageHandler.AddReaction)
+			chats.DELETE("/:id/messages/:messageID/reactions/:emoji", messageHandler.RemoveReaction)
+
+			chats.POST("/:id/attachments", attachmentHandler.UploadAttachment)
+
+			chats.GET("/:id/scheduled-messages", scheduledMessageHandler.GetScheduledMessages)
+			chats.POST("/:id/scheduled-messages", scheduledMessageHandler.ScheduleMessage)
+			chats.PUT("/:id/scheduled-messages/:scheduledID", scheduledMessageHandler.UpdateScheduledMessage)
+			chats.DELETE("/:id/scheduled-messages/:scheduledID", scheduledMessageHandler.CancelScheduledMessage)
+
+			chats.POST("/:id/typing", realtimeHandler.SetTyping)
+		}
+		v1.GET("/ws", authMiddleWare(token, csrf, tokenConfig), realtimeHandler.ServeWS)
+		v1.GET("/events", authMiddleWare(token, csrf, tokenConfig), realtimeHandler.ServeSSE)
+		v1.GET("/mentions", authMiddleWare(token, csrf, tokenConfig), messageHandler.GetMentions)
+		v1.GET("/messages/search", authMiddleWare(token, csrf, tokenConfig), messageHandler.SearchMessages)
+		attachments := v1.Group("/attachments")
+		attachments.Use(authMiddleWare(token, csrf, tokenConfig))
+		{
+			attachments.GET("/:id", attachmentHandler.DownloadAttachment)
+			attachments.GET("/:id/thumbnails/:size", attachmentHandler.DownloadThumbnail)
+		}
+		dms := v1.Group("/dms")
+		dms.Use(authMiddleWare(token, csrf, tokenConfig))
+		{
+			dms.POST("/:userID", chatHandler.GetOrCreateDirectChat)
+		}
 	}
 
 	return &Router{
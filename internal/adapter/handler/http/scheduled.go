@@ -0,0 +1,269 @@
+package httphandler
+
+import (
+	"time"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/HellEaglee/Golang-Chat/internal/core/port"
+	"github.com/HellEaglee/Golang-Chat/internal/core/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ScheduledMessageHandler struct {
+	service     port.ScheduledMessageService
+	chatService port.ChatService
+}
+
+func NewScheduledMessageHandler(service port.ScheduledMessageService, chatService port.ChatService) *ScheduledMessageHandler {
+	return &ScheduledMessageHandler{
+		service:     service,
+		chatService: chatService,
+	}
+}
+
+type scheduledMessageRequest struct {
+	ChatID             string `uri:"id" binding:"required,uuid"`
+	ScheduledMessageID string `uri:"scheduledID" binding:"required,uuid"`
+}
+
+// The text limits leave room for markup like those of sent messages
+type scheduleMessageRequest struct {
+	Text             string    `json:"text" binding:"required,max=8192" example:"Happy **birthday**!"`
+	Format           string    `json:"format" binding:"omitempty,oneof=plain markdown" example:"markdown"`
+	ReplyToMessageID *string   `json:"reply_to_message_id" binding:"omitempty,uuid" example:"6b0f7d9e-2c3a-4f5b-8e1d-9a4c7b2e5f30"`
+	SendAt           time.Time `json:"send_at" binding:"required" example:"2030-01-01T09:00:00Z"`
+}
+
+// ScheduleMessage godoc
+//
+//	@Summary		Schedule a message
+//	@Description	Write a message now and have it sent to the chat at the given time
+//	@Tags			Scheduled messages
+//	@Accept			json
+//	@Produce		json
+//	@Param			id						path		string						true	"Chat ID (UUID)"
+//	@Param			scheduleMessageRequest	body		scheduleMessageRequest		true	"Scheduled message"
+//	@Success		200						{object}	scheduledMessageResponse	"Message scheduled"
+//	@Failure		400						{object}	errorResponse				"Validation error"
+//	@Failure		401						{object}	errorResponse				"Unauthorized error"
+//	@Failure		403						{object}	errorResponse				"Forbidden error"
+//	@Failure		500						{object}	errorResponse				"Internal server error"
+//	@Router			/chats/{id}/scheduled-messages [post]
+func (handler *ScheduledMessageHandler) ScheduleMessage(ctx *gin.Context) {
+	var uri chatMessagesRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	var req scheduleMessageRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	if err := checkParticipant(ctx, handler.chatService, uri.ChatID, userID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	scheduled := &domain.ScheduledMessage{
+		ID:     uuid.New(),
+		ChatID: uuid.MustParse(uri.ChatID),
+		UserID: userID,
+		Text:   req.Text,
+		Format: req.Format,
+		SendAt: req.SendAt,
+	}
+	if req.ReplyToMessageID != nil {
+		replyToMessageID := uuid.MustParse(*req.ReplyToMessageID)
+		scheduled.ReplyToMessageID = &replyToMessageID
+	}
+
+	createdScheduled, err := handler.service.ScheduleMessage(ctx.Request.Context(), scheduled)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	clearDraft(ctx, handler.chatService, uri.ChatID, userID)
+
+	rsp := newScheduledMessageResponse(createdScheduled)
+	handleSuccess(ctx, rsp)
+}
+
+// GetScheduledMessages godoc
+//
+//	@Summary		List scheduled messages
+//	@Description	Get the messages the current user scheduled in the chat that were not sent nor canceled yet, failed ones included
+//	@Tags			Scheduled messages
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string						true	"Chat ID (UUID)"
+//	@Success		200	{array}		scheduledMessageResponse	"Scheduled messages displayed"
+//	@Failure		400	{object}	errorResponse				"Validation error"
+//	@Failure		401	{object}	errorResponse				"Unauthorized error"
+//	@Failure		403	{object}	errorResponse				"Forbidden error"
+//	@Failure		500	{object}	errorResponse				"Internal server error"
+//	@Router			/chats/{id}/scheduled-messages [get]
+func (handler *ScheduledMessageHandler) GetScheduledMessages(ctx *gin.Context) {
+	var uri chatMessagesRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return
+	}
+
+	if err := checkParticipant(ctx, handler.chatService, uri.ChatID, userID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	scheduled, err := handler.service.GetScheduledMessages(ctx.Request.Context(), uri.ChatID, userID.String())
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	scheduledResponses := make([]scheduledMessageResponse, len(scheduled))
+	for i, s := range scheduled {
+		scheduledResponses[i] = newScheduledMessageResponse(&s)
+	}
+
+	total := uint64(len(scheduled))
+	meta := newMeta(total, total, 0)
+	rsp := toMap(meta, scheduledResponses, "scheduled_messages")
+
+	handleSuccess(ctx, rsp)
+}
+
+type updateScheduledMessageRequest struct {
+	Text   *string    `json:"text" binding:"omitempty,min=1,max=8192" example:"Happy **birthday**!!"`
+	Format *string    `json:"format" binding:"omitempty,oneof=plain markdown" example:"markdown"`
+	SendAt *time.Time `json:"send_at" binding:"required_without_all=Text Format" example:"2030-01-01T10:00:00Z"`
+}
+
+// UpdateScheduledMessage godoc
+//
+//	@Summary		Reschedule a message
+//	@Description	Change the send time, the text or the format of a scheduled message that was not sent yet
+//	@Tags			Scheduled messages
+//	@Accept			json
+//	@Produce		json
+//	@Param			id								path		string							true	"Chat ID (UUID)"
+//	@Param			scheduledID						path		string							true	"Scheduled message ID (UUID)"
+//	@Param			updateScheduledMessageRequest	body		updateScheduledMessageRequest	true	"Changes"
+//	@Success		200								{object}	scheduledMessageResponse		"Scheduled message updated"
+//	@Failure		400								{object}	errorResponse					"Validation error"
+//	@Failure		401								{object}	errorResponse					"Unauthorized error"
+//	@Failure		403								{object}	errorResponse					"Forbidden error"
+//	@Failure		404								{object}	errorResponse					"Data not found error"
+//	@Failure		409								{object}	errorResponse					"Data conflict error"
+//	@Failure		500								{object}	errorResponse					"Internal server error"
+//	@Router			/chats/{id}/scheduled-messages/{scheduledID} [put]
+func (handler *ScheduledMessageHandler) UpdateScheduledMessage(ctx *gin.Context) {
+	var req updateScheduledMessageRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError(ctx, err)
+		return
+	}
+
+	scheduled, ok := handler.getOwnScheduledMessage(ctx)
+	if !ok {
+		return
+	}
+
+	if req.Text != nil {
+		scheduled.Text = *req.Text
+	}
+	if req.Format != nil {
+		scheduled.Format = *req.Format
+	}
+	if req.SendAt != nil {
+		scheduled.SendAt = *req.SendAt
+	}
+
+	updatedScheduled, err := handler.service.UpdateScheduledMessage(ctx.Request.Context(), scheduled)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	rsp := newScheduledMessageResponse(updatedScheduled)
+	handleSuccess(ctx, rsp)
+}
+
+// CancelScheduledMessage godoc
+//
+//	@Summary		Cancel a scheduled message
+//	@Description	Cancel a scheduled message that was not sent yet
+//	@Tags			Scheduled messages
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string			true	"Chat ID (UUID)"
+//	@Param			scheduledID	path		string			true	"Scheduled message ID (UUID)"
+//	@Success		200			{object}	response		"Scheduled message canceled"
+//	@Failure		400			{object}	errorResponse	"Validation error"
+//	@Failure		401			{object}	errorResponse	"Unauthorized error"
+//	@Failure		403			{object}	errorResponse	"Forbidden error"
+//	@Failure		404			{object}	errorResponse	"Data not found error"
+//	@Failure		409			{object}	errorResponse	"Data conflict error"
+//	@Failure		500			{object}	errorResponse	"Internal server error"
+//	@Router			/chats/{id}/scheduled-messages/{scheduledID} [delete]
+func (handler *ScheduledMessageHandler) CancelScheduledMessage(ctx *gin.Context) {
+	scheduled, ok := handler.getOwnScheduledMessage(ctx)
+	if !ok {
+		return
+	}
+
+	if err := handler.service.CancelScheduledMessage(ctx.Request.Context(), scheduled); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	handleSuccess(ctx, nil)
+}
+
+// getOwnScheduledMessage is a helper function to load a scheduled message of the current user in the chat;
+// it writes the error response itself
+func (handler *ScheduledMessageHandler) getOwnScheduledMessage(ctx *gin.Context) (*domain.ScheduledMessage, bool) {
+	var uri scheduledMessageRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		validationError(ctx, err)
+		return nil, false
+	}
+
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
+		handleError(ctx, util.ErrUnauthorized)
+		return nil, false
+	}
+
+	if err := checkParticipant(ctx, handler.chatService, uri.ChatID, userID); err != nil {
+		handleError(ctx, err)
+		return nil, false
+	}
+
+	scheduled, err := handler.service.GetScheduledMessage(ctx.Request.Context(), uri.ScheduledMessageID)
+	if err != nil {
+		handleError(ctx, err)
+		return nil, false
+	}
+	// other users' scheduled messages are not revealed
+	if scheduled.ChatID.String() != uri.ChatID || scheduled.UserID != userID {
+		handleError(ctx, util.ErrDataNotFound)
+		return nil, false
+	}
+	return scheduled, true
+}
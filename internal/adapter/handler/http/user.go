@@ -1,7 +1,6 @@
 package httphandler
 
 import (
-	"fmt"
 	"strconv"
 
 	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
@@ -110,17 +109,12 @@ func (handler *UserHandler) GetUser(ctx *gin.Context) {
 //	@Failure		500	{object}	errorResponse	"Internal server error"
 //	@Router			/users/profile [get]
 func (handler *UserHandler) GetProfile(ctx *gin.Context) {
-	userIDI, exists := ctx.Get("user_id")
-	if !exists {
+	userID, err := getAuthUserID(ctx)
+	if err != nil {
 		handleError(ctx, util.ErrForbidden)
 		return
 	}
 
-	userID, ok := userIDI.(uuid.UUID)
-	if !ok {
-		handleError(ctx, fmt.Errorf("invalid user id format"))
-		return
-	}
 	user, err := handler.service.GetUser(ctx.Request.Context(), userID.String())
 	if err != nil {
 		handleError(ctx, err)
@@ -0,0 +1,94 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// maxPixels rejects images that would take too much memory once decoded
+	maxPixels = 40_000_000
+	// jpegQuality is used for opaque thumbnails; images with transparency are kept as PNG
+	jpegQuality = 80
+)
+
+var errTooManyPixels = errors.New("image dimensions are too large")
+
+// Processor decodes JPEG, PNG, GIF and WebP images and scales them with golang.org/x/image
+type Processor struct{}
+
+func New() *Processor {
+	return &Processor{}
+}
+
+func (p *Processor) Thumbnails(r io.Reader, boxes []int) (int, int, []domain.AttachmentThumbnail, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	// check the header before decoding so a tiny file cannot claim gigabytes of pixels
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxPixels {
+		return 0, 0, nil, errTooManyPixels
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	bounds := img.Bounds()
+	thumbnails := make([]domain.AttachmentThumbnail, 0, len(boxes))
+	for _, box := range boxes {
+		width, height := fit(bounds.Dx(), bounds.Dy(), box)
+		scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.BiLinear.Scale(scaled, scaled.Bounds(), img, bounds, draw.Src, nil)
+
+		thumbnail, err := encode(scaled)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		thumbnail.Width, thumbnail.Height = width, height
+		thumbnails = append(thumbnails, thumbnail)
+	}
+	return bounds.Dx(), bounds.Dy(), thumbnails, nil
+}
+
+// fit returns the largest size with the same aspect ratio that fits in a box by box square, without growing
+func fit(width, height, box int) (int, int) {
+	if width <= box && height <= box {
+		return width, height
+	}
+	if width >= height {
+		return box, max(1, height*box/width)
+	}
+	return max(1, width*box/height), box
+}
+
+func encode(img *image.RGBA) (domain.AttachmentThumbnail, error) {
+	var buf bytes.Buffer
+	if img.Opaque() {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return domain.AttachmentThumbnail{}, err
+		}
+		return domain.AttachmentThumbnail{ContentType: "image/jpeg", Data: buf.Bytes()}, nil
+	}
+
+	if err := png.Encode(&buf, img); err != nil {
+		return domain.AttachmentThumbnail{}, err
+	}
+	return domain.AttachmentThumbnail{ContentType: "image/png", Data: buf.Bytes()}, nil
+}
@@ -0,0 +1,52 @@
+package realtime
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// sendBufferSize is how many frames may queue up for a connection before it is treated as a slow consumer
+const sendBufferSize = 64
+
+// Client is a single realtime connection of a user
+type Client struct {
+	UserID uuid.UUID
+	send   chan Frame
+	done   chan struct{}
+	once   sync.Once
+}
+
+func newClient(userID uuid.UUID) *Client {
+	return &Client{
+		UserID: userID,
+		send:   make(chan Frame, sendBufferSize),
+		done:   make(chan struct{}),
+	}
+}
+
+// Frames returns the queue of encoded frames waiting to be written to the connection
+func (c *Client) Frames() <-chan Frame {
+	return c.send
+}
+
+// Done is closed once the client has been disconnected, either by the transport or by the hub
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// enqueue hands the frame over without blocking and reports false when the buffer is full
+func (c *Client) enqueue(frame Frame) bool {
+	select {
+	case c.send <- frame:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Client) close() {
+	c.once.Do(func() {
+		close(c.done)
+	})
+}
@@ -0,0 +1,28 @@
+package realtime
+
+import "github.com/google/uuid"
+
+// EventStreamReset tells a resuming client that events were lost and it has to reload its state over HTTP
+const EventStreamReset = "stream.reset"
+
+// Event is the JSON frame pushed to every connection subscribed to the chat.
+// Signals such as typing indicators carry no id since they are never replayed.
+type Event struct {
+	ID     uint64    `json:"id,omitempty"`
+	Type   string    `json:"type"`
+	ChatID uuid.UUID `json:"chat_id,omitzero"`
+	Data   any       `json:"data,omitempty"`
+}
+
+// ClientMessage is a frame sent by the client over the WebSocket
+type ClientMessage struct {
+	Type      string    `json:"type"`
+	ChatID    uuid.UUID `json:"chat_id,omitzero"`
+	MessageID uuid.UUID `json:"message_id,omitzero"`
+}
+
+// Frame is an encoded event waiting to be written to a connection
+type Frame struct {
+	ID   uint64
+	Data []byte
+}
@@ -0,0 +1,278 @@
+package realtime
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// replayBufferSize is how many recent events are kept to let reconnecting clients resume without gaps
+const replayBufferSize = 1024
+
+type recentEvent struct {
+	chatID uuid.UUID
+	frame  Frame
+}
+
+// Hub keeps track of the realtime connections of this instance and the chats they are subscribed to
+type Hub struct {
+	mu    sync.RWMutex
+	users map[uuid.UUID]map[*Client]struct{}
+	chats map[uuid.UUID]map[*Client]struct{}
+	// subscriptions holds the chats each user is subscribed to, so new connections and membership changes stay in sync
+	subscriptions map[uuid.UUID]map[uuid.UUID]struct{}
+	// lastID is the id of the latest published event
+	lastID uint64
+	// recent holds the latest events in the order they were published, which is the same on every instance
+	recent []recentEvent
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		users:         make(map[uuid.UUID]map[*Client]struct{}),
+		chats:         make(map[uuid.UUID]map[*Client]struct{}),
+		subscriptions: make(map[uuid.UUID]map[uuid.UUID]struct{}),
+	}
+}
+
+// Connect registers a new connection of the user and subscribes it to the given chats.
+// A non-zero lastEventID replays the buffered events the client missed, or queues a stream.reset event
+// when they are no longer available.
+func (h *Hub) Connect(userID uuid.UUID, chatIDs []uuid.UUID, lastEventID uint64) *Client {
+	client := newClient(userID)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.users[userID] == nil {
+		h.users[userID] = make(map[*Client]struct{})
+		h.subscriptions[userID] = make(map[uuid.UUID]struct{})
+	}
+	h.users[userID][client] = struct{}{}
+
+	for _, chatID := range chatIDs {
+		h.subscriptions[userID][chatID] = struct{}{}
+	}
+	for chatID := range h.subscriptions[userID] {
+		h.addToChat(chatID, client)
+	}
+
+	if lastEventID != 0 {
+		h.replay(client, lastEventID)
+	}
+
+	return client
+}
+
+// replay queues the buffered events published after lastEventID for the chats the client is subscribed to. The replay
+// is all or nothing: when lastEventID is not among the buffered events, because it is too old or was never seen by this
+// instance, or when the missed events do not fit in the send buffer, a stream.reset event is queued instead, so the
+// client never resumes with a gap it cannot see.
+func (h *Hub) replay(client *Client, lastEventID uint64) {
+	if lastEventID == h.lastID {
+		return
+	}
+
+	// ids are shared by every instance but are not ordered, so the resume point is found by position
+	position := -1
+	for i := len(h.recent) - 1; i >= 0; i-- {
+		if h.recent[i].frame.ID == lastEventID {
+			position = i
+			break
+		}
+	}
+	if position < 0 {
+		h.reset(client)
+		return
+	}
+
+	var missed []Frame
+	subscriptions := h.subscriptions[client.UserID]
+	for _, event := range h.recent[position+1:] {
+		if _, ok := subscriptions[event.chatID]; ok {
+			missed = append(missed, event.frame)
+		}
+	}
+
+	if len(missed) > cap(client.send)-len(client.send) {
+		h.reset(client)
+		return
+	}
+	for _, frame := range missed {
+		client.enqueue(frame)
+	}
+}
+
+// reset queues a stream.reset event carrying the id of the latest event, from which the client can resume later
+func (h *Hub) reset(client *Client) {
+	frame, err := encode(Event{ID: h.lastID, Type: EventStreamReset})
+	if err != nil {
+		slog.Error("Error encoding realtime event", "type", EventStreamReset, "error", err)
+		return
+	}
+	client.enqueue(frame)
+}
+
+// Disconnect unregisters the connection and closes it
+func (h *Hub) Disconnect(client *Client) {
+	h.mu.Lock()
+	h.remove(client)
+	h.mu.Unlock()
+
+	client.close()
+}
+
+// Subscribe makes every connection of the user receive the events of the chat
+func (h *Hub) Subscribe(userID, chatID uuid.UUID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	clients, ok := h.users[userID]
+	if !ok {
+		return
+	}
+	h.subscriptions[userID][chatID] = struct{}{}
+	for client := range clients {
+		h.addToChat(chatID, client)
+	}
+}
+
+// Unsubscribe stops delivering the events of the chat to the connections of the user
+func (h *Hub) Unsubscribe(userID, chatID uuid.UUID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	clients, ok := h.users[userID]
+	if !ok {
+		return
+	}
+	delete(h.subscriptions[userID], chatID)
+	for client := range clients {
+		h.removeFromChat(chatID, client)
+	}
+}
+
+// UnsubscribeAll stops delivering the events of the chat to anyone, used once the chat is gone
+func (h *Hub) UnsubscribeAll(chatID uuid.UUID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for client := range h.chats[chatID] {
+		delete(h.subscriptions[client.UserID], chatID)
+	}
+	delete(h.chats, chatID)
+}
+
+// Publish pushes the event, numbered by the event bus, to every connection subscribed to its chat,
+// dropping connections that cannot keep up
+func (h *Hub) Publish(event Event) {
+	var slow []*Client
+
+	h.mu.Lock()
+	frame, err := encode(event)
+	if err != nil {
+		h.mu.Unlock()
+		slog.Error("Error encoding realtime event", "type", event.Type, "error", err)
+		return
+	}
+
+	h.lastID = event.ID
+	h.recent = append(h.recent, recentEvent{chatID: event.ChatID, frame: frame})
+	if len(h.recent) > replayBufferSize {
+		h.recent = h.recent[1:]
+	}
+
+	for client := range h.chats[event.ChatID] {
+		if !client.enqueue(frame) {
+			slow = append(slow, client)
+		}
+	}
+	h.mu.Unlock()
+
+	for _, client := range slow {
+		slog.Warn("Dropping slow realtime consumer", "user_id", client.UserID)
+		h.Disconnect(client)
+	}
+}
+
+// Signal pushes a short-lived event to the connections subscribed to its chat, except those of the sender.
+// Signals are not numbered nor kept for replay, and connections that cannot take them simply miss them.
+func (h *Hub) Signal(event Event, senderID uuid.UUID) {
+	event.ID = 0
+	frame, err := encode(event)
+	if err != nil {
+		slog.Error("Error encoding realtime event", "type", event.Type, "error", err)
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for client := range h.chats[event.ChatID] {
+		if client.UserID != senderID {
+			client.enqueue(frame)
+		}
+	}
+}
+
+// SignalUsers pushes a short-lived event to every connection of the given users, regardless of chat subscriptions
+func (h *Hub) SignalUsers(event Event, userIDs []uuid.UUID) {
+	event.ID = 0
+	frame, err := encode(event)
+	if err != nil {
+		slog.Error("Error encoding realtime event", "type", event.Type, "error", err)
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, userID := range userIDs {
+		for client := range h.users[userID] {
+			client.enqueue(frame)
+		}
+	}
+}
+
+func (h *Hub) addToChat(chatID uuid.UUID, client *Client) {
+	if h.chats[chatID] == nil {
+		h.chats[chatID] = make(map[*Client]struct{})
+	}
+	h.chats[chatID][client] = struct{}{}
+}
+
+func (h *Hub) removeFromChat(chatID uuid.UUID, client *Client) {
+	delete(h.chats[chatID], client)
+	if len(h.chats[chatID]) == 0 {
+		delete(h.chats, chatID)
+	}
+}
+
+func (h *Hub) remove(client *Client) {
+	clients, ok := h.users[client.UserID]
+	if !ok {
+		return
+	}
+	if _, ok := clients[client]; !ok {
+		return
+	}
+
+	for chatID := range h.subscriptions[client.UserID] {
+		h.removeFromChat(chatID, client)
+	}
+	delete(clients, client)
+	if len(clients) == 0 {
+		delete(h.users, client.UserID)
+		delete(h.subscriptions, client.UserID)
+	}
+}
+
+func encode(event Event) (Frame, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return Frame{}, err
+	}
+	return Frame{ID: event.ID, Data: data}, nil
+}
@@ -0,0 +1,145 @@
+package realtime
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// drain returns the events queued for the client
+func drain(t *testing.T, client *Client) []Event {
+	t.Helper()
+
+	var events []Event
+	for {
+		select {
+		case frame := <-client.Frames():
+			var event Event
+			if err := json.Unmarshal(frame.Data, &event); err != nil {
+				t.Fatalf("decoding frame: %v", err)
+			}
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+// publisher numbers events the way the event bus does before they reach the hub
+type publisher struct {
+	hub    *Hub
+	nextID uint64
+}
+
+func (p *publisher) publish(chatID uuid.UUID) uint64 {
+	p.nextID++
+	p.hub.Publish(Event{ID: p.nextID, Type: "message.created", ChatID: chatID})
+	return p.nextID
+}
+
+func TestHubReplay(t *testing.T) {
+	chatID, otherChatID := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name string
+		// published is how many events go to the chat the client is subscribed to after it last connected
+		published int
+		// unrelated is how many events go to another chat in between
+		unrelated int
+		// resumeFrom set to -1 resumes from an id the hub never published
+		resumeFrom int
+		wantReset  bool
+		wantEvents int
+	}{
+		{name: "nothing missed", published: 0, wantEvents: 0},
+		{name: "a few missed", published: 3, wantEvents: 3},
+		{name: "exactly a full buffer", published: sendBufferSize, wantEvents: sendBufferSize},
+		{name: "more than a buffer", published: sendBufferSize + 1, wantReset: true},
+		{name: "far more than a buffer", published: replayBufferSize - 1, wantReset: true},
+		{name: "other chats do not count", published: sendBufferSize, unrelated: 100, wantEvents: sendBufferSize},
+		{name: "gone from the replay buffer", published: replayBufferSize + 1, wantReset: true},
+		{name: "id never seen by this instance", published: 2, resumeFrom: -1, wantReset: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := NewHub()
+			events := &publisher{hub: hub, nextID: 1000}
+			userID := uuid.New()
+
+			lastEventID := events.publish(chatID)
+			for i := 0; i < tt.published; i++ {
+				events.publish(chatID)
+				if i < tt.unrelated {
+					events.publish(otherChatID)
+				}
+			}
+			if tt.resumeFrom == -1 {
+				lastEventID = 7
+			}
+
+			client := hub.Connect(userID, []uuid.UUID{chatID}, lastEventID)
+			replayed := drain(t, client)
+
+			if tt.wantReset {
+				if len(replayed) != 1 || replayed[0].Type != EventStreamReset {
+					t.Fatalf("got %d events, want a single %s", len(replayed), EventStreamReset)
+				}
+				if replayed[0].ID != events.nextID {
+					t.Errorf("reset id = %d, want the latest id %d", replayed[0].ID, events.nextID)
+				}
+				return
+			}
+
+			if len(replayed) != tt.wantEvents {
+				t.Fatalf("got %d events, want %d", len(replayed), tt.wantEvents)
+			}
+			previous := lastEventID
+			for _, event := range replayed {
+				if event.ChatID != chatID {
+					t.Errorf("replayed an event of chat %s", event.ChatID)
+				}
+				if event.ID <= previous {
+					t.Errorf("event id %d does not follow %d", event.ID, previous)
+				}
+				previous = event.ID
+			}
+		})
+	}
+}
+
+func TestHubReplayFollowsPublishOrder(t *testing.T) {
+	hub := NewHub()
+	chatID := uuid.New()
+	// the bus numbers events before they are committed, so instances may receive them with decreasing ids
+	for _, id := range []uint64{50, 40, 45, 30, 60} {
+		hub.Publish(Event{ID: id, Type: "message.created", ChatID: chatID})
+	}
+
+	client := hub.Connect(uuid.New(), []uuid.UUID{chatID}, 40)
+	events := drain(t, client)
+
+	var ids []uint64
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	if want := []uint64{45, 30, 60}; !slices.Equal(ids, want) {
+		t.Fatalf("replayed %v, want %v", ids, want)
+	}
+}
+
+func TestHubReplayWithoutLastEventID(t *testing.T) {
+	hub := NewHub()
+	events := &publisher{hub: hub}
+	chatID := uuid.New()
+	for i := 0; i < 5; i++ {
+		events.publish(chatID)
+	}
+
+	client := hub.Connect(uuid.New(), []uuid.UUID{chatID}, 0)
+	if events := drain(t, client); len(events) != 0 {
+		t.Fatalf("got %d events for a fresh connection, want none", len(events))
+	}
+}
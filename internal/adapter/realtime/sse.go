@@ -0,0 +1,68 @@
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// heartbeatPeriod keeps proxies from closing an idle event stream
+	heartbeatPeriod = 25 * time.Second
+	// retryDelay is the reconnection delay suggested to the browser, in milliseconds
+	retryDelay = 3000
+)
+
+// ServeEventStream writes hub events to w as Server-Sent Events until the request context is done
+// or the client is dropped by the hub
+func ServeEventStream(ctx context.Context, hub *Hub, w http.ResponseWriter, userID uuid.UUID, chatIDs []uuid.UUID, lastEventID uint64) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	client := hub.Connect(userID, chatIDs, lastEventID)
+	defer hub.Disconnect(client)
+
+	fmt.Fprintf(w, "retry: %d\n\n", retryDelay)
+	flusher.Flush()
+
+	ticker := time.NewTicker(heartbeatPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case frame := <-client.Frames():
+			// unnumbered signals must not move the Last-Event-ID of the browser
+			if frame.ID != 0 {
+				if _, err := fmt.Fprintf(w, "id: %d\n", frame.ID); err != nil {
+					return
+				}
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", frame.Data); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-client.Done():
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package realtime
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// writeWait is the time allowed to write a frame to the peer
+	writeWait = 10 * time.Second
+	// pongWait is the time allowed to read the next pong from the peer
+	pongWait = 60 * time.Second
+	// pingPeriod must be shorter than pongWait so the peer has time to answer
+	pingPeriod = (pongWait * 9) / 10
+	// maxFrameSize limits frames sent by the peer
+	maxFrameSize = 4096
+)
+
+// ServeWebSocket pumps hub events to the connection until either side goes away,
+// passing the frames sent by the client to onMessage
+func ServeWebSocket(hub *Hub, conn *websocket.Conn, userID uuid.UUID, chatIDs []uuid.UUID, lastEventID uint64, onMessage func(ClientMessage)) {
+	client := hub.Connect(userID, chatIDs, lastEventID)
+	defer hub.Disconnect(client)
+
+	go writePump(conn, client)
+	readPump(conn, onMessage)
+}
+
+// readPump keeps the read deadline moving with pongs, decodes client frames and returns once the connection is closed
+func readPump(conn *websocket.Conn, onMessage func(ClientMessage)) {
+	conn.SetReadLimit(maxFrameSize)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var message ClientMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			// malformed frames are ignored rather than ending the connection
+			continue
+		}
+		onMessage(message)
+	}
+}
+
+// writePump writes queued frames and pings, and closes the connection when the client is disconnected
+func writePump(conn *websocket.Conn, client *Client) {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case frame := <-client.Frames():
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.TextMessage, frame.Data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-client.Done():
+			conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "connection closed"), time.Now().Add(writeWait))
+			return
+		}
+	}
+}
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_messages_chat_id_created_at_id ON messages (chat_id, created_at, id) WHERE deleted_at IS NULL;
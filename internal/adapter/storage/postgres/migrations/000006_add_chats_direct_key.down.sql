@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_chats_direct_key;
+ALTER TABLE chats DROP COLUMN IF EXISTS direct_key;
@@ -0,0 +1,20 @@
+-- Ordered pair of participant ids ("<lower uuid>:<higher uuid>") identifying a direct message chat
+ALTER TABLE chats ADD COLUMN IF NOT EXISTS direct_key VARCHAR(73);
+
+-- Keep the oldest direct chat of every pair that already exists
+UPDATE chats SET direct_key = pairs.direct_key
+FROM (
+    SELECT DISTINCT ON (direct_key) chat_id, direct_key
+    FROM (
+        SELECT cp.chat_id, string_agg(cp.user_id::text, ':' ORDER BY cp.user_id) AS direct_key, MIN(c.created_at) AS created_at
+        FROM chat_participants cp
+        JOIN chats c ON c.id = cp.chat_id
+        WHERE c.is_group = FALSE AND c.deleted_at IS NULL AND cp.deleted_at IS NULL
+        GROUP BY cp.chat_id
+        HAVING COUNT(*) = 2
+    ) candidates
+    ORDER BY direct_key, created_at
+) pairs
+WHERE chats.id = pairs.chat_id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_chats_direct_key ON chats (direct_key) WHERE deleted_at IS NULL;
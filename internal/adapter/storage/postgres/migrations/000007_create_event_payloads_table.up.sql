@@ -0,0 +1,8 @@
+-- Holds events too large for a NOTIFY payload; listeners receive only the row id
+CREATE TABLE IF NOT EXISTS event_payloads (
+    id BIGSERIAL PRIMARY KEY,
+    payload TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_payloads_created_at ON event_payloads (created_at);
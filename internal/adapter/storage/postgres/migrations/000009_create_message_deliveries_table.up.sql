@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS message_deliveries (
+    message_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    delivered_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (message_id, user_id),
+
+    CONSTRAINT fk_message_deliveries_message_id FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE,
+    CONSTRAINT fk_message_deliveries_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,21 @@
+-- Per-chat message sequence; chats.last_seq is the counter CreateMessage increments under the chat row lock
+ALTER TABLE chats ADD COLUMN IF NOT EXISTS last_seq BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS seq BIGINT;
+
+-- Number existing messages, deleted ones included, in the order they were written
+UPDATE messages SET seq = numbered.seq
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY chat_id ORDER BY created_at, id) AS seq
+    FROM messages
+) numbered
+WHERE messages.id = numbered.id;
+
+UPDATE chats SET last_seq = counters.last_seq
+FROM (
+    SELECT chat_id, MAX(seq) AS last_seq FROM messages GROUP BY chat_id
+) counters
+WHERE chats.id = counters.chat_id;
+
+ALTER TABLE messages ALTER COLUMN seq SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_messages_chat_id_seq ON messages (chat_id, seq);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS message_reactions (
+    message_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    emoji VARCHAR(32) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (message_id, user_id, emoji),
+
+    CONSTRAINT fk_message_reactions_message_id FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE,
+    CONSTRAINT fk_message_reactions_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,12 @@
+-- Previous versions of edited messages; a row holds the text as it was before an edit replaced it
+CREATE TABLE IF NOT EXISTS message_edits (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    message_id UUID NOT NULL,
+    text TEXT NOT NULL,
+    written_at TIMESTAMPTZ NOT NULL, -- When this version was written
+    edited_at TIMESTAMPTZ NOT NULL DEFAULT NOW(), -- When it was replaced
+
+    CONSTRAINT fk_message_edits_message_id FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_edits_message_id_edited_at ON message_edits (message_id, edited_at);
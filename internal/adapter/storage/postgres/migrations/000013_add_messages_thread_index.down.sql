@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_messages_reply_to_created_at_id;
@@ -0,0 +1,2 @@
+-- Serves thread pages and the reply counts of root messages
+CREATE INDEX IF NOT EXISTS idx_messages_reply_to_created_at_id ON messages (reply_to_message_id, created_at, id) WHERE deleted_at IS NULL AND reply_to_message_id IS NOT NULL;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS pinned_messages (
+    message_id UUID PRIMARY KEY,
+    chat_id UUID NOT NULL,
+    pinned_by UUID,
+    pinned_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_pinned_messages_message_id FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE,
+    CONSTRAINT fk_pinned_messages_chat_id FOREIGN KEY (chat_id) REFERENCES chats(id) ON DELETE CASCADE,
+    CONSTRAINT fk_pinned_messages_pinned_by FOREIGN KEY (pinned_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_pinned_messages_chat_id_pinned_at ON pinned_messages (chat_id, pinned_at);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS message_mentions (
+    message_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    chat_id UUID NOT NULL,
+    kind VARCHAR(10) NOT NULL DEFAULT 'user',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (message_id, user_id),
+
+    CONSTRAINT fk_message_mentions_message_id FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE,
+    CONSTRAINT fk_message_mentions_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_message_mentions_chat_id FOREIGN KEY (chat_id) REFERENCES chats(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_mentions_user_id_created_at ON message_mentions (user_id, created_at DESC, message_id DESC);
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS attachment_thumbnails;
+
+DROP INDEX IF EXISTS idx_attachments_thumbnail_queue;
+
+ALTER TABLE attachments
+    DROP COLUMN IF EXISTS thumbnail_attempted_at,
+    DROP COLUMN IF EXISTS thumbnail_status,
+    DROP COLUMN IF EXISTS height,
+    DROP COLUMN IF EXISTS width;
@@ -0,0 +1,21 @@
+ALTER TABLE attachments
+    ADD COLUMN IF NOT EXISTS width INT,
+    ADD COLUMN IF NOT EXISTS height INT,
+    ADD COLUMN IF NOT EXISTS thumbnail_status VARCHAR(10) NOT NULL DEFAULT 'none',
+    ADD COLUMN IF NOT EXISTS thumbnail_attempted_at TIMESTAMPTZ;
+
+-- Serves the thumbnail worker looking for images left to process
+CREATE INDEX IF NOT EXISTS idx_attachments_thumbnail_queue ON attachments (created_at) WHERE thumbnail_status IN ('pending', 'processing');
+
+CREATE TABLE IF NOT EXISTS attachment_thumbnails (
+    attachment_id UUID NOT NULL,
+    size VARCHAR(10) NOT NULL,
+    width INT NOT NULL,
+    height INT NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    storage_key VARCHAR(255) NOT NULL UNIQUE,
+
+    PRIMARY KEY (attachment_id, size),
+
+    CONSTRAINT fk_attachment_thumbnails_attachment_id FOREIGN KEY (attachment_id) REFERENCES attachments(id) ON DELETE CASCADE
+);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS link_previews (
+    url TEXT PRIMARY KEY,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    title TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    image_url TEXT NOT NULL DEFAULT '',
+    site_name TEXT NOT NULL DEFAULT '',
+    fetched_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS message_link_previews (
+    message_id UUID NOT NULL,
+    url TEXT NOT NULL,
+
+    PRIMARY KEY (message_id, url),
+
+    CONSTRAINT fk_message_link_previews_message_id FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE,
+    CONSTRAINT fk_message_link_previews_url FOREIGN KEY (url) REFERENCES link_previews(url) ON DELETE CASCADE
+);
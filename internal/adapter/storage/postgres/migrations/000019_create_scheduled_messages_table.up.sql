@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS scheduled_messages (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    chat_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    text TEXT NOT NULL,
+    reply_to_message_id UUID,
+    send_at TIMESTAMPTZ NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    failure_reason TEXT NOT NULL DEFAULT '',
+    claimed_at TIMESTAMPTZ,
+    sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_scheduled_messages_chat_id FOREIGN KEY (chat_id) REFERENCES chats(id) ON DELETE CASCADE,
+    CONSTRAINT fk_scheduled_messages_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Serves the dispatcher looking for due messages
+CREATE INDEX IF NOT EXISTS idx_scheduled_messages_due ON scheduled_messages (send_at) WHERE status IN ('pending', 'sending');
+CREATE INDEX IF NOT EXISTS idx_scheduled_messages_chat_id_user_id ON scheduled_messages (chat_id, user_id, send_at);
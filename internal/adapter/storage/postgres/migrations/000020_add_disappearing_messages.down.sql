@@ -0,0 +1,10 @@
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS fk_messages_reply_to;
+ALTER TABLE messages ADD CONSTRAINT fk_messages_reply_to FOREIGN KEY (reply_to_message_id) REFERENCES messages(id);
+
+DROP INDEX IF EXISTS idx_messages_expires_at;
+
+ALTER TABLE messages
+    DROP COLUMN IF EXISTS expires_at,
+    DROP COLUMN IF EXISTS kind;
+
+ALTER TABLE chats DROP COLUMN IF EXISTS message_ttl;
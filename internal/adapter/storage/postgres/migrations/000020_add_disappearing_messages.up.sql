@@ -0,0 +1,13 @@
+-- Seconds a message lives after being sent, 0 keeps messages forever
+ALTER TABLE chats ADD COLUMN IF NOT EXISTS message_ttl INT NOT NULL DEFAULT 0;
+
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS kind VARCHAR(10) NOT NULL DEFAULT 'user',
+    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+
+-- Serves the reaper looking for expired messages
+CREATE INDEX IF NOT EXISTS idx_messages_expires_at ON messages (expires_at) WHERE expires_at IS NOT NULL;
+
+-- Expired messages are hard deleted, their replies stay and lose the link
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS fk_messages_reply_to;
+ALTER TABLE messages ADD CONSTRAINT fk_messages_reply_to FOREIGN KEY (reply_to_message_id) REFERENCES messages(id) ON DELETE SET NULL;
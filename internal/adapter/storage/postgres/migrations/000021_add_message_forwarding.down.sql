@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_attachment_thumbnails_storage_key;
+DROP INDEX IF EXISTS idx_attachments_storage_key;
+-- Forwarded copies share the files of the original upload; only the oldest row of each file is kept
+DELETE FROM attachments copied USING attachments original
+WHERE copied.storage_key = original.storage_key AND (copied.created_at, copied.id) > (original.created_at, original.id);
+ALTER TABLE attachments ADD CONSTRAINT attachments_storage_key_key UNIQUE (storage_key);
+
+ALTER TABLE messages
+    DROP CONSTRAINT IF EXISTS fk_messages_forwarded_from_user_id,
+    DROP CONSTRAINT IF EXISTS fk_messages_forwarded_from_chat_id,
+    DROP CONSTRAINT IF EXISTS fk_messages_forwarded_from_message_id,
+    DROP COLUMN IF EXISTS forwarded_from_user_id,
+    DROP COLUMN IF EXISTS forwarded_from_chat_id,
+    DROP COLUMN IF EXISTS forwarded_from_message_id;
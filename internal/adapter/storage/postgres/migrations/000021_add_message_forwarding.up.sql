@@ -0,0 +1,13 @@
+-- Where a forwarded message originally came from; forwarding a forward keeps the first origin
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS forwarded_from_message_id UUID,
+    ADD COLUMN IF NOT EXISTS forwarded_from_chat_id UUID,
+    ADD COLUMN IF NOT EXISTS forwarded_from_user_id UUID,
+    ADD CONSTRAINT fk_messages_forwarded_from_message_id FOREIGN KEY (forwarded_from_message_id) REFERENCES messages(id) ON DELETE SET NULL,
+    ADD CONSTRAINT fk_messages_forwarded_from_chat_id FOREIGN KEY (forwarded_from_chat_id) REFERENCES chats(id) ON DELETE SET NULL,
+    ADD CONSTRAINT fk_messages_forwarded_from_user_id FOREIGN KEY (forwarded_from_user_id) REFERENCES users(id) ON DELETE SET NULL;
+
+-- Forwarded attachments are copies pointing at the same files
+ALTER TABLE attachments DROP CONSTRAINT IF EXISTS attachments_storage_key_key;
+CREATE INDEX IF NOT EXISTS idx_attachments_storage_key ON attachments (storage_key);
+CREATE INDEX IF NOT EXISTS idx_attachment_thumbnails_storage_key ON attachment_thumbnails (storage_key);
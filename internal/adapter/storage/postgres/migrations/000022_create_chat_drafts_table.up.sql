@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS chat_drafts (
+    chat_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    text TEXT NOT NULL DEFAULT '',
+    reply_to_message_id UUID,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (chat_id, user_id),
+
+    CONSTRAINT fk_chat_drafts_chat_id FOREIGN KEY (chat_id) REFERENCES chats(id) ON DELETE CASCADE,
+    CONSTRAINT fk_chat_drafts_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_chat_drafts_reply_to_message_id FOREIGN KEY (reply_to_message_id) REFERENCES messages(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_chat_drafts_user_id ON chat_drafts (user_id);
@@ -0,0 +1,4 @@
+-- Formatting is kept apart from the text as spans over it, so that search, mentions and previews see plain text
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS entities JSONB NOT NULL DEFAULT '[]';
+
+ALTER TABLE message_edits ADD COLUMN IF NOT EXISTS entities JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,9 @@
+-- Only the thumbnails of the oldest attachment sharing each file are kept
+DELETE FROM attachment_thumbnails copied
+USING attachment_thumbnails original, attachments copied_attachment, attachments original_attachment
+WHERE copied.storage_key = original.storage_key
+    AND copied_attachment.id = copied.attachment_id
+    AND original_attachment.id = original.attachment_id
+    AND (copied_attachment.created_at, copied_attachment.id) > (original_attachment.created_at, original_attachment.id);
+
+ALTER TABLE attachment_thumbnails ADD CONSTRAINT attachment_thumbnails_storage_key_key UNIQUE (storage_key);
@@ -0,0 +1,2 @@
+-- Thumbnails of forwarded attachments point at the same files as those of the original, like the attachments do
+ALTER TABLE attachment_thumbnails DROP CONSTRAINT IF EXISTS attachment_thumbnails_storage_key_key;
@@ -0,0 +1,2 @@
+-- Numbers the events of the bus, so that every instance gives an event the same id when streaming it to clients
+CREATE SEQUENCE IF NOT EXISTS event_ids;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS presence_connections (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    away BOOLEAN NOT NULL DEFAULT FALSE,
+    seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_presence_connections_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_presence_connections_user_id ON presence_connections (user_id);
+-- Serves the sweep of connections whose instance went away
+CREATE INDEX IF NOT EXISTS idx_presence_connections_seen_at ON presence_connections (seen_at);
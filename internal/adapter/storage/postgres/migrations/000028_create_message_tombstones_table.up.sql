@@ -0,0 +1,14 @@
+-- Expired messages leave a tombstone under a new sequence number, so replaying clients learn they are gone
+CREATE TABLE IF NOT EXISTS message_tombstones (
+    message_id UUID PRIMARY KEY,
+    chat_id UUID NOT NULL,
+    seq BIGINT NOT NULL,
+    user_id UUID NOT NULL,
+    kind VARCHAR(10) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    deleted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_message_tombstones_chat_id FOREIGN KEY (chat_id) REFERENCES chats(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_message_tombstones_chat_id_seq ON message_tombstones (chat_id, seq);
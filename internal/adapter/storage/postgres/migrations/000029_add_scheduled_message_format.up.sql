@@ -0,0 +1,2 @@
+-- The text is kept as written and formatted when the message is sent
+ALTER TABLE scheduled_messages ADD COLUMN IF NOT EXISTS format VARCHAR(10) NOT NULL DEFAULT 'plain';
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/HellEaglee/Golang-Chat/internal/adapter/storage/postgres"
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/HellEaglee/Golang-Chat/internal/core/util"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AttachmentRepository struct {
+	db *postgres.DB
+}
+
+func NewAttachmentRepository(db *postgres.DB) *AttachmentRepository {
+	return &AttachmentRepository{db: db}
+}
+
+func (r *AttachmentRepository) CreateAttachment(ctx context.Context, attachment *domain.Attachment) (*domain.Attachment, error) {
+	if err := r.db.WithContext(ctx).Create(attachment).Error; err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// GetAttachmentByID hides the attachments of deleted messages
+func (r *AttachmentRepository) GetAttachmentByID(ctx context.Context, id string) (*domain.Attachment, error) {
+	var attachment domain.Attachment
+	err := r.db.WithContext(ctx).Preload("Thumbnails").
+		Joins("LEFT JOIN messages m ON m.id = attachments.message_id").
+		Where("attachments.id = ? AND (attachments.message_id IS NULL OR m.deleted_at IS NULL)", id).
+		First(&attachment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, util.ErrDataNotFound
+		}
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// ClaimPendingThumbnail marks the oldest image waiting for thumbnails as being processed and returns it.
+// Claims older than staleAfter are taken over, in case the instance working on them went away.
+// It returns util.ErrDataNotFound when there is nothing to do.
+func (r *AttachmentRepository) ClaimPendingThumbnail(ctx context.Context, staleAfter time.Duration) (*domain.Attachment, error) {
+	var attachment domain.Attachment
+	query := `UPDATE attachments SET thumbnail_status = $1, thumbnail_attempted_at = NOW()
+		WHERE id = (
+			SELECT id FROM attachments
+			WHERE thumbnail_status = $2 OR (thumbnail_status = $1 AND thumbnail_attempted_at < $3)
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`
+
+	err := r.db.WithContext(ctx).
+		Raw(query, domain.ThumbnailStatusProcessing, domain.ThumbnailStatusPending, time.Now().Add(-staleAfter)).
+		Scan(&attachment).Error
+	if err != nil {
+		return nil, err
+	}
+	if attachment.ID == uuid.Nil {
+		return nil, util.ErrDataNotFound
+	}
+	return &attachment, nil
+}
+
+// SaveAttachmentThumbnails stores the dimensions and thumbnails of the attachment and marks it as ready
+func (r *AttachmentRepository) SaveAttachmentThumbnails(ctx context.Context, attachment *domain.Attachment) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("attachment_id = ?", attachment.ID).Delete(&domain.AttachmentThumbnail{}).Error; err != nil {
+			return err
+		}
+		if len(attachment.Thumbnails) > 0 {
+			if err := tx.Create(&attachment.Thumbnails).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&domain.Attachment{}).Where("id = ?", attachment.ID).Updates(map[string]any{
+			"width":            attachment.Width,
+			"height":           attachment.Height,
+			"thumbnail_status": domain.ThumbnailStatusReady,
+		}).Error
+	})
+}
+
+func (r *AttachmentRepository) UpdateAttachmentThumbnailStatus(ctx context.Context, id, status string) error {
+	if err := r.db.WithContext(ctx).Model(&domain.Attachment{}).Where("id = ?", id).Update("thumbnail_status", status).Error; err != nil {
+		return err
+	}
+	return nil
+}
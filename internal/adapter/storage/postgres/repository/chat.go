@@ -2,10 +2,14 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/HellEaglee/Golang-Chat/internal/adapter/storage/postgres"
 	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/HellEaglee/Golang-Chat/internal/core/util"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type ChatRepository struct {
@@ -24,9 +28,50 @@ func (r *ChatRepository) CreateChat(ctx context.Context, chat *domain.Chat) (*do
 	return chat, nil
 }
 
+// GetOrCreateDirectChat returns the live direct chat matching chat.DirectKey, creating it together with its participants if there is none.
+// Participants who left an existing chat are brought back, so the pair can always talk again.
+func (r *ChatRepository) GetOrCreateDirectChat(ctx context.Context, chat *domain.Chat) (*domain.Chat, error) {
+	var directChat domain.Chat
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := `INSERT INTO chats (id, is_group, direct_key) VALUES ($1, FALSE, $2)
+			ON CONFLICT (direct_key) WHERE deleted_at IS NULL DO NOTHING
+			RETURNING *`
+
+		if err := tx.Raw(query, chat.ID, chat.DirectKey).Scan(&directChat).Error; err != nil {
+			return err
+		}
+		if directChat.ID != uuid.Nil {
+			for i := range chat.Participants {
+				chat.Participants[i].ChatID = directChat.ID
+			}
+			return tx.Create(&chat.Participants).Error
+		}
+
+		if err := tx.Where("direct_key = ? AND deleted_at IS NULL", chat.DirectKey).First(&directChat).Error; err != nil {
+			return err
+		}
+		restore := `INSERT INTO chat_participants (chat_id, user_id, role) VALUES ($1, $2, $3)
+			ON CONFLICT (chat_id, user_id) DO UPDATE SET role = EXCLUDED.role, joined_at = NOW(), left_at = NULL, updated_at = NOW(), deleted_at = NULL
+			WHERE chat_participants.deleted_at IS NOT NULL`
+		for _, participant := range chat.Participants {
+			if err := tx.Exec(restore, directChat.ID, participant.UserID, participant.Role).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &directChat, nil
+}
+
 func (r *ChatRepository) GetChatByID(ctx context.Context, id string) (*domain.Chat, error) {
 	var chat domain.Chat
 	if err := r.db.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", id).First(&chat).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, util.ErrDataNotFound
+		}
 		return nil, err
 	}
 	return &chat, nil
@@ -40,6 +85,19 @@ func (r *ChatRepository) GetChatsByUserID(ctx context.Context, id string) ([]dom
 	return chats, nil
 }
 
+func (r *ChatRepository) GetContactIDsByUserID(ctx context.Context, id string) ([]uuid.UUID, error) {
+	var contactIDs []uuid.UUID
+	query := `SELECT DISTINCT other.user_id FROM chat_participants me
+		JOIN chat_participants other ON other.chat_id = me.chat_id AND other.deleted_at IS NULL
+		JOIN chats c ON c.id = me.chat_id AND c.deleted_at IS NULL
+		WHERE me.user_id = $1 AND me.deleted_at IS NULL AND other.user_id <> $1`
+
+	if err := r.db.WithContext(ctx).Raw(query, id).Scan(&contactIDs).Error; err != nil {
+		return nil, err
+	}
+	return contactIDs, nil
+}
+
 func (r *ChatRepository) GetChats(ctx context.Context, skip uint64, limit uint64) ([]domain.Chat, error) {
 	var chats []domain.Chat
 	if err := r.db.WithContext(ctx).Limit(int(limit)).Offset(int(skip)).Find(&chats).Error; err != nil {
@@ -55,6 +113,22 @@ func (r *ChatRepository) UpdateChat(ctx context.Context, chat *domain.Chat) (*do
 	if err := r.db.WithContext(ctx).Raw(query, chat.ID, chat.Name, chat.IsGroup).Scan(&updatedChat).Error; err != nil {
 		return nil, err
 	}
+	if updatedChat.ID == uuid.Nil {
+		return nil, util.ErrDataNotFound
+	}
+	return &updatedChat, nil
+}
+
+func (r *ChatRepository) UpdateChatMessageTTL(ctx context.Context, id string, ttl int) (*domain.Chat, error) {
+	var updatedChat domain.Chat
+	query := `UPDATE chats SET message_ttl = $2, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL RETURNING *`
+
+	if err := r.db.WithContext(ctx).Raw(query, id, ttl).Scan(&updatedChat).Error; err != nil {
+		return nil, err
+	}
+	if updatedChat.ID == uuid.Nil {
+		return nil, util.ErrDataNotFound
+	}
 	return &updatedChat, nil
 }
 
@@ -66,16 +140,25 @@ func (r *ChatRepository) DeleteChat(ctx context.Context, id string) error {
 }
 
 // ----------------------------------------------------CHAT_PARTICIPANTS----------------------------------------------------
+// CreateChatParticipant adds a user to a chat, reviving the membership row if the user has left before
 func (r *ChatRepository) CreateChatParticipant(ctx context.Context, chatParticipant *domain.ChatParticipant) (*domain.ChatParticipant, error) {
-	if err := r.db.WithContext(ctx).Create(chatParticipant).Error; err != nil {
+	var createdChatParticipant domain.ChatParticipant
+	query := `INSERT INTO chat_participants (chat_id, user_id, role) VALUES ($1, $2, $3)
+		ON CONFLICT (chat_id, user_id) DO UPDATE SET role = EXCLUDED.role, joined_at = NOW(), left_at = NULL, updated_at = NOW(), deleted_at = NULL
+		RETURNING *`
+
+	if err := r.db.WithContext(ctx).Raw(query, chatParticipant.ChatID, chatParticipant.UserID, chatParticipant.Role).Scan(&createdChatParticipant).Error; err != nil {
 		return nil, err
 	}
-	return chatParticipant, nil
+	return &createdChatParticipant, nil
 }
 
 func (r *ChatRepository) GetChatParticipantByChatIDUserID(ctx context.Context, chatID, userID string) (*domain.ChatParticipant, error) {
 	var chatParticipant domain.ChatParticipant
 	if err := r.db.WithContext(ctx).Where("chat_id = $1 AND user_id = $2 and deleted_at IS NULL", chatID, userID).First(&chatParticipant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, util.ErrDataNotFound
+		}
 		return nil, err
 	}
 	return &chatParticipant, nil
@@ -83,7 +166,7 @@ func (r *ChatRepository) GetChatParticipantByChatIDUserID(ctx context.Context, c
 
 func (r *ChatRepository) GetChatParticipantsByChatID(ctx context.Context, id string) ([]domain.ChatParticipant, error) {
 	var chatParticipants []domain.ChatParticipant
-	if err := r.db.WithContext(ctx).Where("chat_id = ?", id).Find(&chatParticipants).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("User").Where("chat_id = ?", id).Find(&chatParticipants).Error; err != nil {
 		return nil, err
 	}
 	return chatParticipants, nil
@@ -96,15 +179,51 @@ func (r *ChatRepository) UpdateChatParticipant(ctx context.Context, chatParticip
 	if err := r.db.WithContext(ctx).Raw(query, chatParticipant.ChatID, chatParticipant.UserID, chatParticipant.Role).Scan(&updatedChatParticipant).Error; err != nil {
 		return nil, err
 	}
+	if updatedChatParticipant.ChatID == uuid.Nil {
+		return nil, util.ErrDataNotFound
+	}
 	return &updatedChatParticipant, nil
 }
 
 func (r *ChatRepository) DeleteChatParticipant(ctx context.Context, chatID, userID string) error {
+	now := time.Now()
 	if err := r.db.WithContext(ctx).Model(&domain.ChatParticipant{}).Where("chat_id = $1 AND user_id = $2", chatID, userID).Updates(map[string]interface{}{
-		"deleted_at": time.Now,
-		"left_at":    time.Now,
+		"deleted_at": now,
+		"left_at":    now,
 	}).Error; err != nil {
 		return err
 	}
 	return nil
 }
+
+// ----------------------------------------------------CHAT_DRAFTS----------------------------------------------------
+func (r *ChatRepository) SaveChatDraft(ctx context.Context, draft *domain.ChatDraft) (*domain.ChatDraft, error) {
+	var savedDraft domain.ChatDraft
+	query := `INSERT INTO chat_drafts (chat_id, user_id, text, reply_to_message_id, updated_at) VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (chat_id, user_id) DO UPDATE SET text = EXCLUDED.text, reply_to_message_id = EXCLUDED.reply_to_message_id, updated_at = NOW()
+		RETURNING *`
+
+	if err := r.db.WithContext(ctx).Raw(query, draft.ChatID, draft.UserID, draft.Text, draft.ReplyToMessageID).Scan(&savedDraft).Error; err != nil {
+		return nil, err
+	}
+	return &savedDraft, nil
+}
+
+func (r *ChatRepository) GetChatDraftsByUserID(ctx context.Context, userID string) ([]domain.ChatDraft, error) {
+	var drafts []domain.ChatDraft
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&drafts).Error; err != nil {
+		return nil, err
+	}
+	return drafts, nil
+}
+
+func (r *ChatRepository) DeleteChatDraft(ctx context.Context, chatID, userID string) error {
+	result := r.db.WithContext(ctx).Where("chat_id = ? AND user_id = ?", chatID, userID).Delete(&domain.ChatDraft{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return util.ErrDataNotFound
+	}
+	return nil
+}
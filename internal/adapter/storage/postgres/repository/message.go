@@ -1,10 +1,22 @@
 package repository
 
 import (
+	"bytes"
+	"cmp"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
 
 	"github.com/HellEaglee/Golang-Chat/internal/adapter/storage/postgres"
 	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/HellEaglee/Golang-Chat/internal/core/util"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type MessageRepository struct {
@@ -16,44 +28,407 @@ func NewMessageRepository(db *postgres.DB) *MessageRepository {
 }
 
 // ----------------------------------------------------MESSAGES----------------------------------------------------
+// CreateMessage takes the next sequence number of the chat; the counter row stays locked until the transaction ends,
+// so concurrent messages of the same chat are numbered one after another. The mentions of the message are saved with it
+// and the uploads listed in its attachments are linked to it, as long as they are unsent uploads of the sender to the chat;
+// a forwarded message instead carries copies of the attachments of the original, which are saved as they are.
+// Its link previews are linked as well, starting as pending the first time a link shows up.
+// When the chat has a message TTL, the message is given its expiry time.
 func (r *MessageRepository) CreateMessage(ctx context.Context, message *domain.Message) (*domain.Message, error) {
-	if err := r.db.WithContext(ctx).Create(message).Error; err != nil {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createMessage(tx, message)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return message, nil
 }
 
+// CreateMessages saves the messages like CreateMessage does, all of them or none
+func (r *MessageRepository) CreateMessages(ctx context.Context, messages []*domain.Message) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, message := range messages {
+			if err := createMessage(tx, message); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func createMessage(tx *gorm.DB, message *domain.Message) error {
+	var chat struct {
+		LastSeq    int64
+		MessageTTL int
+	}
+	query := `UPDATE chats SET last_seq = last_seq + 1 WHERE id = $1 AND deleted_at IS NULL RETURNING last_seq, message_ttl`
+	if err := tx.Raw(query, message.ChatID).Scan(&chat).Error; err != nil {
+		return err
+	}
+	if chat.LastSeq == 0 {
+		return util.ErrDataNotFound
+	}
+	message.Seq = chat.LastSeq
+
+	if chat.MessageTTL > 0 {
+		if message.CreatedAt.IsZero() {
+			message.CreatedAt = time.Now()
+		}
+		expiresAt := message.CreatedAt.Add(time.Duration(chat.MessageTTL) * time.Second)
+		message.ExpiresAt = &expiresAt
+	}
+
+	// plain text is stored with an empty list of entities rather than a JSON null
+	if message.Entities == nil {
+		message.Entities = []domain.MessageEntity{}
+	}
+	if err := tx.Omit("Attachments", "LinkPreviews").Create(message).Error; err != nil {
+		return err
+	}
+	if message.ForwardedFromMessageID != nil {
+		if err := copyAttachments(tx, message); err != nil {
+			return err
+		}
+	} else if err := linkAttachments(tx, message); err != nil {
+		return err
+	}
+	if err := linkPreviews(tx, message); err != nil {
+		return err
+	}
+	return tx.Model(&domain.Chat{}).Where("id = ?", message.ChatID).Updates(map[string]any{
+		"last_message":    message.Text,
+		"last_message_at": message.CreatedAt,
+	}).Error
+}
+
+func linkAttachments(tx *gorm.DB, message *domain.Message) error {
+	if len(message.Attachments) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(message.Attachments))
+	for i, attachment := range message.Attachments {
+		ids[i] = attachment.ID
+	}
+
+	result := tx.Model(&domain.Attachment{}).
+		Where("id IN ? AND chat_id = ? AND user_id = ? AND message_id IS NULL", ids, message.ChatID, message.UserID).
+		Update("message_id", message.ID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected != int64(len(ids)) {
+		return util.ErrInvalidAttachment
+	}
+
+	message.Attachments = nil
+	return tx.Preload("Thumbnails").Where("message_id = ?", message.ID).Order("created_at ASC, id ASC").Find(&message.Attachments).Error
+}
+
+// copyAttachments saves the attachment copies of a forwarded message, which share their files with the originals
+func copyAttachments(tx *gorm.DB, message *domain.Message) error {
+	if len(message.Attachments) == 0 {
+		return nil
+	}
+
+	for i := range message.Attachments {
+		message.Attachments[i].MessageID = &message.ID
+	}
+	return tx.Create(&message.Attachments).Error
+}
+
+// linkPreviews links the message to the cached previews of its links and loads them back
+func linkPreviews(tx *gorm.DB, message *domain.Message) error {
+	if len(message.LinkPreviews) == 0 {
+		return nil
+	}
+
+	urls := make([]string, len(message.LinkPreviews))
+	for i, preview := range message.LinkPreviews {
+		urls[i] = preview.URL
+		err := tx.Exec(`INSERT INTO link_previews (url) VALUES ($1) ON CONFLICT (url) DO NOTHING`, preview.URL).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Exec(`INSERT INTO message_link_previews (message_id, url) VALUES ($1, $2) ON CONFLICT DO NOTHING`, message.ID, preview.URL).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	message.LinkPreviews = nil
+	return tx.Where("url IN ?", urls).Find(&message.LinkPreviews).Error
+}
+
 func (r *MessageRepository) GetMessageByID(ctx context.Context, id string) (*domain.Message, error) {
 	var message domain.Message
-	if err := r.db.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", id).First(&message).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Attachments.Thumbnails").Preload("LinkPreviews").Where("id = ? AND deleted_at IS NULL", id).Scopes(notExpired).First(&message).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, util.ErrDataNotFound
+		}
 		return nil, err
 	}
 	return &message, nil
 }
 
-func (r *MessageRepository) GetMessagesByChatID(ctx context.Context, chatID string) ([]domain.Message, error) {
+func (r *MessageRepository) GetMessagesByIDs(ctx context.Context, chatID string, ids []uuid.UUID) ([]domain.Message, error) {
+	var messages []domain.Message
+	if err := r.db.WithContext(ctx).Preload("Attachments.Thumbnails").
+		Where("chat_id = ? AND id IN ? AND deleted_at IS NULL", chatID, ids).Scopes(notExpired).
+		Order("seq ASC").
+		Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// GetMessagesByChatID returns a keyset window of the chat history in chronological order,
+// restricted to the replies of page.ThreadID when it is set
+func (r *MessageRepository) GetMessagesByChatID(ctx context.Context, chatID string, page domain.MessagePage) ([]domain.Message, error) {
 	var messages []domain.Message
-	if err := r.db.WithContext(ctx).Where("chat_id = ? AND deleted_at IS NULL", chatID).Find(&messages).Error; err != nil {
+	query := r.db.WithContext(ctx).Where("chat_id = ? AND deleted_at IS NULL", chatID).Scopes(notExpired)
+	if page.ThreadID != nil {
+		query = query.Where("reply_to_message_id = ?", page.ThreadID)
+	}
+	switch {
+	case page.After != nil:
+		query = query.Where("(created_at, id) > (?, ?)", page.After.CreatedAt, page.After.ID).Order("created_at ASC, id ASC")
+	case page.Before != nil:
+		query = query.Where("(created_at, id) < (?, ?)", page.Before.CreatedAt, page.Before.ID).Order("created_at DESC, id DESC")
+	default:
+		query = query.Order("created_at DESC, id DESC")
+	}
+
+	if err := query.Preload("Attachments.Thumbnails").Preload("LinkPreviews").Limit(page.Limit).Find(&messages).Error; err != nil {
 		return nil, err
 	}
+	if page.After == nil {
+		slices.Reverse(messages)
+	}
 	return messages, nil
 }
 
+// GetMessagesAfterSeq returns expired messages as deleted ones, under the sequence number of their tombstone
+func (r *MessageRepository) GetMessagesAfterSeq(ctx context.Context, chatID string, afterSeq int64, limit int) ([]domain.Message, error) {
+	var messages []domain.Message
+	if err := r.db.WithContext(ctx).Unscoped().Preload("Attachments.Thumbnails").Preload("LinkPreviews").
+		Where("chat_id = ? AND seq > ?", chatID, afterSeq).Scopes(notExpired).
+		Order("seq ASC").
+		Limit(limit).
+		Find(&messages).Error; err != nil {
+		return nil, err
+	}
+
+	var tombstones []domain.MessageTombstone
+	if err := r.db.WithContext(ctx).
+		Where("chat_id = ? AND seq > ?", chatID, afterSeq).
+		Order("seq ASC").
+		Limit(limit).
+		Find(&tombstones).Error; err != nil {
+		return nil, err
+	}
+	if len(tombstones) == 0 {
+		return messages, nil
+	}
+
+	for _, tombstone := range tombstones {
+		messages = append(messages, domain.Message{
+			ID:        tombstone.MessageID,
+			ChatID:    tombstone.ChatID,
+			Seq:       tombstone.Seq,
+			UserID:    tombstone.UserID,
+			Kind:      tombstone.Kind,
+			CreatedAt: tombstone.CreatedAt,
+			UpdatedAt: tombstone.DeletedAt,
+			DeletedAt: gorm.DeletedAt{Time: tombstone.DeletedAt, Valid: true},
+		})
+	}
+	slices.SortFunc(messages, func(a, b domain.Message) int {
+		return cmp.Compare(a.Seq, b.Seq)
+	})
+	return messages[:min(len(messages), limit)], nil
+}
+
+// UpdateMessage stores the current text in message_edits before replacing it
 func (r *MessageRepository) UpdateMessage(ctx context.Context, message *domain.Message) (*domain.Message, error) {
+	entities := message.Entities
+	if entities == nil {
+		entities = []domain.MessageEntity{}
+	}
+	encodedEntities, err := json.Marshal(entities)
+	if err != nil {
+		return nil, err
+	}
+
 	var updatedMessage domain.Message
-	query := `UPDATE messages SET text = $2, is_edited = TRUE, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL RETURNING *`
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		archive := `INSERT INTO message_edits (message_id, text, entities, written_at)
+			SELECT id, text, entities, updated_at FROM messages WHERE id = $1 AND deleted_at IS NULL
+			FOR UPDATE`
+		result := tx.Exec(archive, message.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return util.ErrDataNotFound
+		}
 
-	if err := r.db.WithContext(ctx).Raw(query, message.ChatID, message.Text).Scan(&updatedMessage).Error; err != nil {
+		query := `UPDATE messages SET text = $2, entities = $3, is_edited = TRUE, updated_at = NOW()
+			WHERE id = $1 AND deleted_at IS NULL RETURNING *`
+		if err := tx.Raw(query, message.ID, message.Text, string(encodedEntities)).Scan(&updatedMessage).Error; err != nil {
+			return err
+		}
+
+		// the previews follow the links of the new text
+		if err := tx.Exec(`DELETE FROM message_link_previews WHERE message_id = $1`, message.ID).Error; err != nil {
+			return err
+		}
+		updatedMessage.LinkPreviews = message.LinkPreviews
+		return linkPreviews(tx, &updatedMessage)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return &updatedMessage, nil
 }
 
-func (r *MessageRepository) DeleteMessage(ctx context.Context, id string) error {
-	if err := r.db.WithContext(ctx).Where("id = ?", id).Delete(&domain.Message{}).Error; err != nil {
+// notExpired hides the expired messages the reaper did not get to yet
+func notExpired(db *gorm.DB) *gorm.DB {
+	return db.Where("(expires_at IS NULL OR expires_at > NOW())")
+}
+
+// DeleteExpiredMessages hard deletes up to limit expired messages, deleted ones included, and returns them along with
+// the storage keys of their attachment files that no forwarded copy still uses. Each message leaves a
+// domain.MessageTombstone behind. Chats whose last message expired fall back to the text and time of the latest one left.
+func (r *MessageRepository) DeleteExpiredMessages(ctx context.Context, limit int) ([]domain.Message, []string, error) {
+	var messages []domain.Message
+	var unusedKeys []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Preload("Attachments.Thumbnails").
+			Where("expires_at <= NOW()").
+			Order("expires_at").
+			Limit(limit).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Find(&messages).Error
+		if err != nil || len(messages) == 0 {
+			return err
+		}
+
+		ids := make([]uuid.UUID, len(messages))
+		byChat := make(map[uuid.UUID][]domain.Message)
+		for i, message := range messages {
+			ids[i] = message.ID
+			byChat[message.ChatID] = append(byChat[message.ChatID], message)
+		}
+		// chats are locked in the same order by every reaper
+		chatIDs := slices.SortedFunc(maps.Keys(byChat), func(a, b uuid.UUID) int {
+			return bytes.Compare(a[:], b[:])
+		})
+
+		for _, chatID := range chatIDs {
+			if err := createTombstones(tx, chatID, byChat[chatID]); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("message_id IN ?", ids).Delete(&domain.MessageRead{}).Error; err != nil {
+			return err
+		}
+		// receipts, reactions, edits, pins, mentions and attachments go along through ON DELETE CASCADE
+		if err := tx.Unscoped().Where("id IN ?", ids).Delete(&domain.Message{}).Error; err != nil {
+			return err
+		}
+
+		// the aggregates turn a chat left without messages into a single row, which falls back to when the chat was created
+		query := `UPDATE chats c SET (last_message, last_message_at) = (
+				SELECT COALESCE(MAX(latest.text), ''), COALESCE(MAX(latest.created_at), c.created_at) FROM (
+					SELECT m.text, m.created_at FROM messages m
+					WHERE m.chat_id = c.id AND m.deleted_at IS NULL
+					ORDER BY m.created_at DESC, m.id DESC
+					LIMIT 1
+				) latest
+			)
+			WHERE c.id IN ?`
+		if err := tx.Exec(query, chatIDs).Error; err != nil {
+			return err
+		}
+
+		var keys []string
+		for _, message := range messages {
+			for _, attachment := range message.Attachments {
+				keys = append(keys, attachment.StorageKey)
+				for _, thumbnail := range attachment.Thumbnails {
+					keys = append(keys, thumbnail.StorageKey)
+				}
+			}
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+
+		var usedKeys []string
+		query = `SELECT storage_key FROM attachments WHERE storage_key IN ?
+			UNION SELECT storage_key FROM attachment_thumbnails WHERE storage_key IN ?`
+		if err := tx.Raw(query, keys, keys).Scan(&usedKeys).Error; err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if !slices.Contains(usedKeys, key) {
+				unusedKeys = append(unusedKeys, key)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return messages, unusedKeys, nil
+}
+
+// createTombstones numbers the tombstones of the messages of the chat after its last message, in the order the
+// messages were sent
+func createTombstones(tx *gorm.DB, chatID uuid.UUID, messages []domain.Message) error {
+	var lastSeq int64
+	query := `UPDATE chats SET last_seq = last_seq + $2 WHERE id = $1 RETURNING last_seq`
+	if err := tx.Raw(query, chatID, len(messages)).Scan(&lastSeq).Error; err != nil {
 		return err
 	}
-	return nil
+
+	slices.SortFunc(messages, func(a, b domain.Message) int {
+		return cmp.Compare(a.Seq, b.Seq)
+	})
+	tombstones := make([]domain.MessageTombstone, len(messages))
+	for i, message := range messages {
+		tombstones[i] = domain.MessageTombstone{
+			MessageID: message.ID,
+			ChatID:    chatID,
+			Seq:       lastSeq - int64(len(messages)-1-i),
+			UserID:    message.UserID,
+			Kind:      message.Kind,
+			CreatedAt: message.CreatedAt,
+			DeletedAt: time.Now(),
+		}
+	}
+	return tx.Create(&tombstones).Error
+}
+
+// DeleteMessage soft deletes the message and unpins it, as deleted messages cannot stay pinned
+func (r *MessageRepository) DeleteMessage(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("message_id = ?", id).Delete(&domain.PinnedMessage{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&domain.Message{}).Error
+	})
+}
+
+// GetMessageEditsByMessageID returns the previous versions of the message, oldest first
+func (r *MessageRepository) GetMessageEditsByMessageID(ctx context.Context, id string) ([]domain.MessageEdit, error) {
+	var edits []domain.MessageEdit
+	if err := r.db.WithContext(ctx).Where("message_id = ?", id).Order("edited_at ASC").Find(&edits).Error; err != nil {
+		return nil, err
+	}
+	return edits, nil
 }
 
 // ----------------------------------------------------MESSAGE_READS----------------------------------------------------
@@ -78,3 +453,261 @@ func (r *MessageRepository) DeleteMessageRead(ctx context.Context, messageID, us
 	}
 	return nil
 }
+
+// ----------------------------------------------------RECEIPTS----------------------------------------------------
+// markMessagesQuery acknowledges, for one user, every message of the chat written by someone else up to and including upTo
+const markMessagesQuery = `INSERT INTO %s (message_id, user_id)
+	SELECT m.id, $2 FROM messages m
+	WHERE m.chat_id = $1 AND m.user_id <> $2 AND m.deleted_at IS NULL AND (m.created_at, m.id) <= ($3, $4)
+	ON CONFLICT (message_id, user_id) DO NOTHING`
+
+// MarkMessagesDelivered returns how many messages were newly marked as delivered
+func (r *MessageRepository) MarkMessagesDelivered(ctx context.Context, upTo *domain.Message, userID string) (int64, error) {
+	result := r.db.WithContext(ctx).Exec(fmt.Sprintf(markMessagesQuery, "message_deliveries"), upTo.ChatID, userID, upTo.CreatedAt, upTo.ID)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// MarkMessagesRead returns how many messages were newly marked as read; reading a message also delivers it
+func (r *MessageRepository) MarkMessagesRead(ctx context.Context, upTo *domain.Message, userID string) (int64, error) {
+	var read int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(fmt.Sprintf(markMessagesQuery, "message_deliveries"), upTo.ChatID, userID, upTo.CreatedAt, upTo.ID).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Exec(fmt.Sprintf(markMessagesQuery, "message_reads"), upTo.ChatID, userID, upTo.CreatedAt, upTo.ID)
+		read = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return read, nil
+}
+
+// GetMessageReceipts returns the delivery and read times of the message for every other active participant of its chat
+func (r *MessageRepository) GetMessageReceipts(ctx context.Context, messageID string) ([]domain.MessageReceipt, error) {
+	var receipts []domain.MessageReceipt
+	query := `SELECT cp.user_id, md.delivered_at, mr.read_at FROM messages m
+		JOIN chat_participants cp ON cp.chat_id = m.chat_id AND cp.user_id <> m.user_id AND cp.deleted_at IS NULL
+		LEFT JOIN message_deliveries md ON md.message_id = m.id AND md.user_id = cp.user_id
+		LEFT JOIN message_reads mr ON mr.message_id = m.id AND mr.user_id = cp.user_id
+		WHERE m.id = $1 AND m.deleted_at IS NULL
+		ORDER BY cp.joined_at`
+
+	if err := r.db.WithContext(ctx).Raw(query, messageID).Scan(&receipts).Error; err != nil {
+		return nil, err
+	}
+	return receipts, nil
+}
+
+// ----------------------------------------------------REACTIONS----------------------------------------------------
+// CreateMessageReaction returns util.ErrConflictingData if the user already reacted to the message with the emoji
+func (r *MessageRepository) CreateMessageReaction(ctx context.Context, reaction *domain.MessageReaction) (*domain.MessageReaction, error) {
+	var createdReaction domain.MessageReaction
+	query := `INSERT INTO message_reactions (message_id, user_id, emoji) VALUES ($1, $2, $3)
+		ON CONFLICT (message_id, user_id, emoji) DO NOTHING
+		RETURNING *`
+
+	if err := r.db.WithContext(ctx).Raw(query, reaction.MessageID, reaction.UserID, reaction.Emoji).Scan(&createdReaction).Error; err != nil {
+		return nil, err
+	}
+	if createdReaction.MessageID == uuid.Nil {
+		return nil, util.ErrConflictingData
+	}
+	return &createdReaction, nil
+}
+
+func (r *MessageRepository) DeleteMessageReaction(ctx context.Context, reaction *domain.MessageReaction) error {
+	result := r.db.WithContext(ctx).
+		Where("message_id = ? AND user_id = ? AND emoji = ?", reaction.MessageID, reaction.UserID, reaction.Emoji).
+		Delete(&domain.MessageReaction{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return util.ErrDataNotFound
+	}
+	return nil
+}
+
+// GetReactionCounts aggregates the reactions of the messages per emoji, in the order each emoji was first used
+func (r *MessageRepository) GetReactionCounts(ctx context.Context, messageIDs []uuid.UUID, userID string) ([]domain.ReactionCount, error) {
+	var counts []domain.ReactionCount
+	if len(messageIDs) == 0 {
+		return counts, nil
+	}
+
+	query := `SELECT message_id, emoji, COUNT(*) AS count, BOOL_OR(user_id = ?) AS reacted
+		FROM message_reactions
+		WHERE message_id IN ?
+		GROUP BY message_id, emoji
+		ORDER BY message_id, MIN(created_at)`
+
+	if err := r.db.WithContext(ctx).Raw(query, userID, messageIDs).Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// ----------------------------------------------------THREADS----------------------------------------------------
+// GetThreadSummaries returns the reply count and last reply time of the messages that have replies
+func (r *MessageRepository) GetThreadSummaries(ctx context.Context, messageIDs []uuid.UUID) ([]domain.ThreadSummary, error) {
+	var summaries []domain.ThreadSummary
+	if len(messageIDs) == 0 {
+		return summaries, nil
+	}
+
+	query := `SELECT reply_to_message_id AS message_id, COUNT(*) AS reply_count, MAX(created_at) AS last_reply_at
+		FROM messages
+		WHERE reply_to_message_id IN ? AND deleted_at IS NULL
+		GROUP BY reply_to_message_id`
+
+	if err := r.db.WithContext(ctx).Raw(query, messageIDs).Scan(&summaries).Error; err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+// ----------------------------------------------------PINS----------------------------------------------------
+// CreatePinnedMessage returns util.ErrConflictingData if the message is already pinned,
+// and util.ErrPinLimitReached if the chat would go over maxPins; zero means no limit
+func (r *MessageRepository) CreatePinnedMessage(ctx context.Context, pin *domain.PinnedMessage, maxPins int) (*domain.PinnedMessage, error) {
+	var createdPin domain.PinnedMessage
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// lock the chat so concurrent pins cannot both slip under the limit
+		var chatIDs []uuid.UUID
+		if err := tx.Raw(`SELECT id FROM chats WHERE id = $1 FOR UPDATE`, pin.ChatID).Scan(&chatIDs).Error; err != nil {
+			return err
+		}
+		if len(chatIDs) == 0 {
+			return util.ErrDataNotFound
+		}
+
+		query := `INSERT INTO pinned_messages (message_id, chat_id, pinned_by) VALUES ($1, $2, $3)
+			ON CONFLICT (message_id) DO NOTHING
+			RETURNING *`
+		if err := tx.Raw(query, pin.MessageID, pin.ChatID, pin.PinnedBy).Scan(&createdPin).Error; err != nil {
+			return err
+		}
+		if createdPin.MessageID == uuid.Nil {
+			return util.ErrConflictingData
+		}
+
+		if maxPins > 0 {
+			var count int64
+			if err := tx.Model(&domain.PinnedMessage{}).Where("chat_id = ?", pin.ChatID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > int64(maxPins) {
+				return util.ErrPinLimitReached
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &createdPin, nil
+}
+
+// GetPinnedMessagesByChatID returns the pinned messages of the chat, most recently pinned first
+func (r *MessageRepository) GetPinnedMessagesByChatID(ctx context.Context, chatID string) ([]domain.PinnedMessage, error) {
+	var pins []domain.PinnedMessage
+	if err := r.db.WithContext(ctx).Preload("Message.Attachments.Thumbnails").Preload("Message.LinkPreviews").Preload("Message").
+		Where("chat_id = ?", chatID).
+		Order("pinned_at DESC").
+		Find(&pins).Error; err != nil {
+		return nil, err
+	}
+	return pins, nil
+}
+
+func (r *MessageRepository) DeletePinnedMessage(ctx context.Context, messageID string) error {
+	result := r.db.WithContext(ctx).Where("message_id = ?", messageID).Delete(&domain.PinnedMessage{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return util.ErrDataNotFound
+	}
+	return nil
+}
+
+// ----------------------------------------------------MENTIONS----------------------------------------------------
+// GetMentionsByUserID returns the mentions of the user in the chats they still participate in, newest first
+func (r *MessageRepository) GetMentionsByUserID(ctx context.Context, userID string, page domain.MentionPage) ([]domain.MessageMention, error) {
+	var mentions []domain.MessageMention
+	query := r.db.WithContext(ctx).
+		Select(`message_mentions.*, EXISTS (
+			SELECT 1 FROM message_reads r WHERE r.message_id = message_mentions.message_id AND r.user_id = message_mentions.user_id
+		) AS is_read`).
+		Joins("JOIN messages m ON m.id = message_mentions.message_id AND m.deleted_at IS NULL").
+		Joins("JOIN chat_participants p ON p.chat_id = message_mentions.chat_id AND p.user_id = message_mentions.user_id AND p.deleted_at IS NULL").
+		Where("message_mentions.user_id = ?", userID)
+	if page.Before != nil {
+		query = query.Where("(message_mentions.created_at, message_mentions.message_id) < (?, ?)", page.Before.CreatedAt, page.Before.ID)
+	}
+	if page.UnreadOnly {
+		query = query.Where("NOT EXISTS (SELECT 1 FROM message_reads r WHERE r.message_id = message_mentions.message_id AND r.user_id = message_mentions.user_id)")
+	}
+
+	err := query.Preload("Message.Attachments.Thumbnails").Preload("Message.LinkPreviews").Preload("Message").
+		Order("message_mentions.created_at DESC, message_mentions.message_id DESC").
+		Limit(page.Limit).
+		Find(&mentions).Error
+	if err != nil {
+		return nil, err
+	}
+	return mentions, nil
+}
+
+// ----------------------------------------------------SEARCH----------------------------------------------------
+// searchSnippet escapes the text before highlighting it, so that the snippet can be shown as HTML as it is
+const searchSnippet = `ts_headline('simple', replace(replace(replace(m.text, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), q.query,
+	'StartSel=<mark>, StopSel=</mark>, MaxWords=30, MinWords=10, MaxFragments=2')`
+
+// SearchMessages matches the query against the messages of the chats the user currently takes part in,
+// ordered by rank and then from newest to oldest
+func (r *MessageRepository) SearchMessages(ctx context.Context, userID string, search domain.MessageSearch) ([]domain.MessageSearchResult, error) {
+	matches := r.db.WithContext(ctx).Table("messages m").
+		Select("m.id AS message_id, m.created_at, ts_rank(m.search_vector, q.query) AS rank, "+searchSnippet+" AS snippet").
+		Joins("CROSS JOIN websearch_to_tsquery('simple', ?) AS q(query)", search.Query).
+		Joins("JOIN chat_participants p ON p.chat_id = m.chat_id AND p.user_id = ? AND p.deleted_at IS NULL", userID).
+		Where("m.search_vector @@ q.query AND m.kind = ? AND m.deleted_at IS NULL", domain.MessageKindUser).
+		Where("(m.expires_at IS NULL OR m.expires_at > NOW())")
+	if search.ChatID != nil {
+		matches = matches.Where("m.chat_id = ?", search.ChatID)
+	}
+	if search.SenderID != nil {
+		matches = matches.Where("m.user_id = ?", search.SenderID)
+	}
+	if search.From != nil {
+		matches = matches.Where("m.created_at >= ?", search.From)
+	}
+	if search.To != nil {
+		matches = matches.Where("m.created_at < ?", search.To)
+	}
+	if search.HasAttachment {
+		matches = matches.Where("EXISTS (SELECT 1 FROM attachments a WHERE a.message_id = m.id)")
+	}
+
+	var results []domain.MessageSearchResult
+	query := r.db.WithContext(ctx).Table("(?) AS results", matches)
+	if search.After != nil {
+		query = query.Where("(rank, created_at, message_id) < (CAST(? AS REAL), ?, ?)", search.After.Rank, search.After.CreatedAt, search.After.ID)
+	}
+
+	err := query.Preload("Message.Attachments.Thumbnails").Preload("Message.LinkPreviews").Preload("Message").
+		Order("rank DESC, created_at DESC, message_id DESC").
+		Limit(search.Limit).
+		Find(&results).Error
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
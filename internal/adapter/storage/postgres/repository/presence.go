@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/HellEaglee/Golang-Chat/internal/adapter/storage/postgres"
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PresenceRepository struct {
+	db *postgres.DB
+}
+
+func NewPresenceRepository(db *postgres.DB) *PresenceRepository {
+	return &PresenceRepository{db: db}
+}
+
+// SavePresenceConnection holds a lock on the user while it runs, so changes to the connections of one user
+// are seen in the same order by every instance
+func (r *PresenceRepository) SavePresenceConnection(ctx context.Context, connection *domain.PresenceConnection, staleAfter time.Duration) (before, after []domain.PresenceConnection, err error) {
+	userID := connection.UserID.String()
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if before, err = lockPresenceConnections(tx, userID, staleAfter); err != nil {
+			return err
+		}
+
+		err = tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"away", "seen_at"}),
+		}).Create(connection).Error
+		if err != nil {
+			return err
+		}
+
+		after, err = livePresenceConnections(tx, userID, staleAfter)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return before, after, nil
+}
+
+func (r *PresenceRepository) DeletePresenceConnection(ctx context.Context, userID, id string, staleAfter time.Duration) (before, after []domain.PresenceConnection, err error) {
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if before, err = lockPresenceConnections(tx, userID, staleAfter); err != nil {
+			return err
+		}
+
+		if err := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&domain.PresenceConnection{}).Error; err != nil {
+			return err
+		}
+
+		after, err = livePresenceConnections(tx, userID, staleAfter)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return before, after, nil
+}
+
+func (r *PresenceRepository) GetPresenceConnectionsByUserIDs(ctx context.Context, userIDs []uuid.UUID, staleAfter time.Duration) ([]domain.PresenceConnection, error) {
+	var connections []domain.PresenceConnection
+	if len(userIDs) == 0 {
+		return connections, nil
+	}
+
+	err := r.db.WithContext(ctx).
+		Where("user_id IN ? AND seen_at > ?", userIDs, time.Now().Add(-staleAfter)).
+		Find(&connections).Error
+	if err != nil {
+		return nil, err
+	}
+	return connections, nil
+}
+
+func (r *PresenceRepository) TouchPresenceConnections(ctx context.Context, ids []uuid.UUID, seenAt time.Time) ([]domain.PresenceConnection, error) {
+	var touched []domain.PresenceConnection
+	if len(ids) == 0 {
+		return touched, nil
+	}
+
+	err := r.db.WithContext(ctx).Model(&touched).Clauses(clause.Returning{}).
+		Where("id IN ?", ids).
+		Update("seen_at", seenAt).Error
+	if err != nil {
+		return nil, err
+	}
+	return touched, nil
+}
+
+// DeleteStalePresenceConnections hands every stale connection to a single caller, however many instances sweep at once
+func (r *PresenceRepository) DeleteStalePresenceConnections(ctx context.Context, staleAfter time.Duration) ([]domain.PresenceConnection, error) {
+	var deleted []domain.PresenceConnection
+	err := r.db.WithContext(ctx).Clauses(clause.Returning{}).
+		Where("seen_at <= ?", time.Now().Add(-staleAfter)).
+		Delete(&deleted).Error
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// lockPresenceConnections locks the user against concurrent presence changes and returns their live connections
+func lockPresenceConnections(tx *gorm.DB, userID string, staleAfter time.Duration) ([]domain.PresenceConnection, error) {
+	// NO KEY UPDATE leaves the user free to be referenced by new rows meanwhile
+	if err := tx.Exec("SELECT 1 FROM users WHERE id = ? FOR NO KEY UPDATE", userID).Error; err != nil {
+		return nil, err
+	}
+	return livePresenceConnections(tx, userID, staleAfter)
+}
+
+func livePresenceConnections(tx *gorm.DB, userID string, staleAfter time.Duration) ([]domain.PresenceConnection, error) {
+	var connections []domain.PresenceConnection
+	if err := tx.Where("user_id = ? AND seen_at > ?", userID, time.Now().Add(-staleAfter)).Find(&connections).Error; err != nil {
+		return nil, err
+	}
+	return connections, nil
+}
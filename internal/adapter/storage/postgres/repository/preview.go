@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/HellEaglee/Golang-Chat/internal/adapter/storage/postgres"
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+)
+
+type LinkPreviewRepository struct {
+	db *postgres.DB
+}
+
+func NewLinkPreviewRepository(db *postgres.DB) *LinkPreviewRepository {
+	return &LinkPreviewRepository{db: db}
+}
+
+func (r *LinkPreviewRepository) UpdateLinkPreview(ctx context.Context, preview *domain.LinkPreview) error {
+	err := r.db.WithContext(ctx).Model(&domain.LinkPreview{}).Where("url = ?", preview.URL).Updates(map[string]any{
+		"status":      preview.Status,
+		"title":       preview.Title,
+		"description": preview.Description,
+		"image_url":   preview.ImageURL,
+		"site_name":   preview.SiteName,
+		"fetched_at":  preview.FetchedAt,
+	}).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/HellEaglee/Golang-Chat/internal/adapter/storage/postgres"
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/HellEaglee/Golang-Chat/internal/core/util"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ScheduledMessageRepository struct {
+	db *postgres.DB
+}
+
+func NewScheduledMessageRepository(db *postgres.DB) *ScheduledMessageRepository {
+	return &ScheduledMessageRepository{db: db}
+}
+
+func (r *ScheduledMessageRepository) CreateScheduledMessage(ctx context.Context, scheduled *domain.ScheduledMessage) (*domain.ScheduledMessage, error) {
+	if err := r.db.WithContext(ctx).Create(scheduled).Error; err != nil {
+		return nil, err
+	}
+	return scheduled, nil
+}
+
+func (r *ScheduledMessageRepository) GetScheduledMessageByID(ctx context.Context, id string) (*domain.ScheduledMessage, error) {
+	var scheduled domain.ScheduledMessage
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&scheduled).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, util.ErrDataNotFound
+		}
+		return nil, err
+	}
+	return &scheduled, nil
+}
+
+func (r *ScheduledMessageRepository) GetScheduledMessagesByChatIDUserID(ctx context.Context, chatID, userID string) ([]domain.ScheduledMessage, error) {
+	var scheduled []domain.ScheduledMessage
+	statuses := []string{domain.ScheduledStatusPending, domain.ScheduledStatusSending, domain.ScheduledStatusFailed}
+	err := r.db.WithContext(ctx).
+		Where("chat_id = ? AND user_id = ? AND status IN ?", chatID, userID, statuses).
+		Order("send_at, id").
+		Find(&scheduled).Error
+	if err != nil {
+		return nil, err
+	}
+	return scheduled, nil
+}
+
+// UpdatePendingScheduledMessage saves the text, format, reply and send time or the cancellation of the scheduled message,
+// as long as the dispatcher did not pick it up yet
+func (r *ScheduledMessageRepository) UpdatePendingScheduledMessage(ctx context.Context, scheduled *domain.ScheduledMessage) (*domain.ScheduledMessage, error) {
+	var updated []domain.ScheduledMessage
+	result := r.db.WithContext(ctx).Model(&updated).Clauses(clause.Returning{}).
+		Where("id = ? AND status = ?", scheduled.ID, domain.ScheduledStatusPending).
+		Updates(map[string]any{
+			"text":                scheduled.Text,
+			"format":              scheduled.Format,
+			"reply_to_message_id": scheduled.ReplyToMessageID,
+			"send_at":             scheduled.SendAt,
+			"status":              scheduled.Status,
+			"updated_at":          time.Now(),
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, util.ErrScheduledMessageNotPending
+	}
+	return &updated[0], nil
+}
+
+// ClaimDueScheduledMessages marks up to limit due messages as being sent and returns them.
+// Claims older than staleAfter are taken over, in case the instance sending them went away.
+func (r *ScheduledMessageRepository) ClaimDueScheduledMessages(ctx context.Context, limit int, staleAfter time.Duration) ([]domain.ScheduledMessage, error) {
+	var scheduled []domain.ScheduledMessage
+	query := `UPDATE scheduled_messages SET status = $1, claimed_at = NOW(), updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM scheduled_messages
+			WHERE (status = $2 AND send_at <= NOW()) OR (status = $1 AND claimed_at < $3)
+			ORDER BY send_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`
+
+	err := r.db.WithContext(ctx).
+		Raw(query, domain.ScheduledStatusSending, domain.ScheduledStatusPending, time.Now().Add(-staleAfter), limit).
+		Scan(&scheduled).Error
+	if err != nil {
+		return nil, err
+	}
+	return scheduled, nil
+}
+
+// MessageExists also sees deleted messages, a scheduled message deleted right after it went out was still sent
+func (r *ScheduledMessageRepository) MessageExists(ctx context.Context, id string) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Unscoped().Model(&domain.Message{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *ScheduledMessageRepository) UpdateScheduledMessageStatus(ctx context.Context, id, status, reason string) error {
+	updates := map[string]any{
+		"status":         status,
+		"failure_reason": reason,
+		"claimed_at":     nil,
+		"updated_at":     time.Now(),
+	}
+	if status == domain.ScheduledStatusSent {
+		updates["sent_at"] = time.Now()
+	}
+	if err := r.db.WithContext(ctx).Model(&domain.ScheduledMessage{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -2,9 +2,13 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/HellEaglee/Golang-Chat/internal/adapter/storage/postgres"
 	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/HellEaglee/Golang-Chat/internal/core/util"
+	"gorm.io/gorm"
 )
 
 type UserRepository struct {
@@ -25,6 +29,9 @@ func (r *UserRepository) CreateUser(ctx context.Context, user *domain.User) (*do
 func (r *UserRepository) GetUserByID(ctx context.Context, id string) (*domain.User, error) {
 	var user domain.User
 	if err := r.db.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", id).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, util.ErrDataNotFound
+		}
 		return nil, err
 	}
 	return &user, nil
@@ -33,6 +40,9 @@ func (r *UserRepository) GetUserByID(ctx context.Context, id string) (*domain.Us
 func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
 	var user domain.User
 	if err := r.db.WithContext(ctx).Where("email = ? AND deleted_at IS NULL", email).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, util.ErrDataNotFound
+		}
 		return nil, err
 	}
 	return &user, nil
@@ -56,6 +66,13 @@ func (r *UserRepository) UpdateUser(ctx context.Context, user *domain.User) (*do
 	return &updatedUser, nil
 }
 
+func (r *UserRepository) UpdateUserLastSeen(ctx context.Context, id string, lastSeenAt time.Time) error {
+	if err := r.db.WithContext(ctx).Exec("UPDATE users SET last_seen_at = ? WHERE id = ? AND deleted_at IS NULL", lastSeenAt, id).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
 func (r *UserRepository) DeleteUser(ctx context.Context, id string) error {
 	if err := r.db.WithContext(ctx).Where("id = ?", id).Delete(&domain.User{}).Error; err != nil {
 		return err
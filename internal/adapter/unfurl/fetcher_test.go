@@ -0,0 +1,152 @@
+package unfurl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+)
+
+func TestPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"8.8.8.8", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fc00::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"100.64.0.1", false},
+		{"198.18.0.1", false},
+		{"224.0.0.1", false},
+		{"255.255.255.255", false},
+		{"2001:db8::1", false},
+		// IPv4 addresses wrapped in IPv6 are judged as IPv4
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:169.254.169.254", false},
+		{"::ffff:8.8.8.8", true},
+		{"64:ff9b::7f00:1", false},
+	}
+
+	for _, tt := range tests {
+		if got := publicAddr(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("publicAddr(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	base, _ := url.Parse("https://example.com/articles/1")
+
+	tests := []struct {
+		name string
+		page string
+		want domain.LinkPreview
+	}{
+		{
+			name: "plain HTML",
+			page: `<html><head><title>Page title</title><meta name="description" content="About the page"></head></html>`,
+			want: domain.LinkPreview{Title: "Page title", Description: "About the page"},
+		},
+		{
+			name: "OpenGraph wins",
+			page: `<head><title>Plain</title>
+				<meta name="twitter:title" content="Twitter">
+				<meta property="og:title" content="OpenGraph">
+				<meta name="description" content="Plain description">
+				<meta property="og:description" content="OpenGraph description">
+				<meta property="og:site_name" content="Example">
+				<meta property="og:image" content="https://cdn.example.com/a.png"></head>`,
+			want: domain.LinkPreview{Title: "OpenGraph", Description: "OpenGraph description", SiteName: "Example", ImageURL: "https://cdn.example.com/a.png"},
+		},
+		{
+			name: "Twitter card over plain HTML",
+			page: `<head><title>Plain</title><meta name="twitter:title" content="Twitter"><meta name="twitter:image" content="/b.png"></head>`,
+			want: domain.LinkPreview{Title: "Twitter", ImageURL: "https://example.com/b.png"},
+		},
+		{
+			name: "relative image",
+			page: `<head><meta property="og:title" content="T"><meta property="og:image" content=" ../img/c.png "></head>`,
+			want: domain.LinkPreview{Title: "T", ImageURL: "https://example.com/img/c.png"},
+		},
+		{
+			name: "script image is dropped",
+			page: `<head><meta property="og:title" content="T"><meta property="og:image" content="javascript:alert(1)"></head>`,
+			want: domain.LinkPreview{Title: "T"},
+		},
+		{
+			name: "first value of a key wins",
+			page: `<head><meta property="og:title" content="First"><meta property="OG:TITLE" content="Second"></head>`,
+			want: domain.LinkPreview{Title: "First"},
+		},
+		{
+			name: "whitespace collapses",
+			page: "<head><title>\n  Spread \t over\n lines  </title></head>",
+			want: domain.LinkPreview{Title: "Spread over lines"},
+		},
+		{
+			name: "body is not read",
+			page: `<head></head><body><meta property="og:title" content="Too late"><title>Too late</title></body>`,
+			want: domain.LinkPreview{},
+		},
+		{
+			name: "not HTML at all",
+			page: "just some text",
+			want: domain.LinkPreview{},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := parse(strings.NewReader(tt.page), base); *got != tt.want {
+			t.Errorf("%s: parse = %+v, want %+v", tt.name, *got, tt.want)
+		}
+	}
+}
+
+func TestClean(t *testing.T) {
+	tests := []struct {
+		text  string
+		limit int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"  a  b  ", 10, "a b"},
+		{"exactly ten", 11, "exactly ten"},
+		{"one two three", 8, "one two…"},
+		{"ééééé", 3, "éé…"},
+	}
+
+	for _, tt := range tests {
+		if got := clean(tt.text, tt.limit); got != tt.want {
+			t.Errorf("clean(%q, %d) = %q, want %q", tt.text, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestFetchRefusesLocalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<head><title>Internal</title></head>`))
+	}))
+	defer server.Close()
+
+	_, err := New().Fetch(context.Background(), server.URL)
+	if !errors.Is(err, errForbiddenAddress) {
+		t.Fatalf("fetching %s: error = %v, want %v", server.URL, err, errForbiddenAddress)
+	}
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ThumbnailStatusNone       = "none"
+	ThumbnailStatusPending    = "pending"
+	ThumbnailStatusProcessing = "processing"
+	ThumbnailStatusReady      = "ready"
+	ThumbnailStatusFailed     = "failed"
+)
+
+// Attachment is a file uploaded to a chat; it stays unlinked until it is sent along with a message
+type Attachment struct {
+	ID          uuid.UUID
+	ChatID      uuid.UUID
+	UserID      uuid.UUID
+	MessageID   *uuid.UUID
+	FileName    string
+	ContentType string
+	Size        int64
+	StorageKey  string
+	// Width and Height are only known for images, once their thumbnails have been generated
+	Width                *int
+	Height               *int
+	ThumbnailStatus      string `gorm:"default:none"`
+	ThumbnailAttemptedAt *time.Time
+	CreatedAt            time.Time
+
+	Thumbnails []AttachmentThumbnail
+}
+
+type AttachmentThumbnail struct {
+	AttachmentID uuid.UUID
+	Size         string
+	Width        int
+	Height       int
+	ContentType  string
+	StorageKey   string
+	// Data holds the encoded thumbnail until it is written to the blob store
+	Data []byte `gorm:"-"`
+}
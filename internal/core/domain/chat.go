@@ -7,25 +7,36 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	ChatRoleAdmin     = "admin"
+	ChatRoleModerator = "moderator"
+	ChatRoleMember    = "member"
+)
+
 type Chat struct {
 	ID            uuid.UUID
 	Name          *string
 	IsGroup       bool
+	DirectKey     *string
 	LastMessage   string
 	LastMessageAt time.Time
+	LastSeq       int64
+	MessageTTL    int // seconds messages live after being sent; zero keeps them forever
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 	DeletedAt     gorm.DeletedAt
 
 	Participants []ChatParticipant
 	Messages     []Message
+	// Draft is the unsent message of the user the chat was loaded for
+	Draft *ChatDraft `gorm:"-"`
 }
 
 type ChatParticipant struct {
 	ChatID    uuid.UUID
 	UserID    uuid.UUID
-	Role      string
-	JoinedAt  time.Time
+	Role      string    `gorm:"default:member"`
+	JoinedAt  time.Time `gorm:"default:now()"`
 	LeftAt    *time.Time
 	CreatedAt time.Time
 	UpdatedAt time.Time
@@ -38,3 +49,16 @@ type ChatParticipant struct {
 func (ChatParticipant) TableName() string {
 	return "chat_participants"
 }
+
+// ChatDraft is what a user was writing in a chat, kept on the server so that their other devices pick it up
+type ChatDraft struct {
+	ChatID           uuid.UUID
+	UserID           uuid.UUID
+	Text             string
+	ReplyToMessageID *uuid.UUID
+	UpdatedAt        time.Time
+}
+
+func (ChatDraft) TableName() string {
+	return "chat_drafts"
+}
@@ -0,0 +1,22 @@
+package domain
+
+const (
+	EntityBold   = "bold"
+	EntityItalic = "italic"
+	EntityCode   = "code"
+	EntityPre    = "pre"
+	EntityLink   = "link"
+	EntityQuote  = "quote"
+)
+
+// MessageEntity marks a formatted span of the plain text of a message. Offset and Length count UTF-16 code units,
+// the way browsers and mobile platforms index strings.
+type MessageEntity struct {
+	Type   string `json:"type"`
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+	// URL is the target of a link
+	URL string `json:"url,omitempty"`
+	// Language is the optional language of a code block
+	Language string `json:"language,omitempty"`
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+const (
+	EventMessageCreated     EventType = "message.created"
+	EventMessageUpdated     EventType = "message.updated"
+	EventMessageDeleted     EventType = "message.deleted"
+	EventMessageDelivered   EventType = "message.delivered"
+	EventMessageRead        EventType = "message.read"
+	EventReactionAdded      EventType = "reaction.added"
+	EventReactionRemoved    EventType = "reaction.removed"
+	EventMessagePinned      EventType = "message.pinned"
+	EventMessageUnpinned    EventType = "message.unpinned"
+	EventAttachmentUpdated  EventType = "attachment.updated"
+	EventMessagePreviewed   EventType = "message.previewed"
+	EventChatCreated        EventType = "chat.created"
+	EventChatUpdated        EventType = "chat.updated"
+	EventChatDeleted        EventType = "chat.deleted"
+	EventDraftUpdated       EventType = "draft.updated"
+	EventParticipantJoined  EventType = "participant.joined"
+	EventParticipantLeft    EventType = "participant.left"
+	EventParticipantUpdated EventType = "participant.updated"
+	EventTypingStarted      EventType = "typing.started"
+	EventTypingStopped      EventType = "typing.stopped"
+	EventPresenceChanged    EventType = "presence.changed"
+)
+
+type Event struct {
+	// ID is assigned by the event bus on publishing. It is unique across instances, and every instance receives
+	// the events in the same order, although the ids do not have to be increasing in that order.
+	ID          uint64
+	Type        EventType
+	ChatID      uuid.UUID
+	ActorID     uuid.UUID
+	Chat        *Chat            `json:",omitempty"`
+	Message     *Message         `json:",omitempty"`
+	Participant *ChatParticipant `json:",omitempty"`
+	Reaction    *MessageReaction `json:",omitempty"`
+	Pin         *PinnedMessage   `json:",omitempty"`
+	Attachment  *Attachment      `json:",omitempty"`
+	Presence    *Presence        `json:",omitempty"`
+	Draft       *ChatDraft       `json:",omitempty"`
+	Recipients  []uuid.UUID      `json:",omitempty"`
+	OccurredAt  time.Time
+}
+
+func NewEvent(eventType EventType, chatID, actorID uuid.UUID) *Event {
+	return &Event{
+		Type:       eventType,
+		ChatID:     chatID,
+		ActorID:    actorID,
+		OccurredAt: time.Now(),
+	}
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MentionKindUser = "user"
+	MentionKindAll  = "all"
+	MentionKindHere = "here"
+)
+
+type MessageMention struct {
+	MessageID uuid.UUID
+	UserID    uuid.UUID
+	ChatID    uuid.UUID
+	Kind      string
+	CreatedAt time.Time
+	// IsRead tells whether the mentioned user has read the message; it is only filled by the mention feed
+	IsRead bool `gorm:"->"`
+
+	Message Message
+}
+
+func (MessageMention) TableName() string {
+	return "message_mentions"
+}
+
+// MentionPage is a keyset window of the mention feed, newest first
+type MentionPage struct {
+	Before     *MessageCursor
+	Limit      int
+	UnreadOnly bool
+}
@@ -7,21 +7,73 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	MessageKindUser = "user"
+	// MessageKindSystem marks the notices the server posts on behalf of UserID, such as a TTL change
+	MessageKindSystem = "system"
+)
+
+const (
+	MessageFormatPlain = "plain"
+	// MessageFormatMarkdown is the Markdown subset the server parses into entities
+	MessageFormatMarkdown = "markdown"
+	// MessageFormatHTML is only an output format, messages are never written in it
+	MessageFormatHTML = "html"
+)
+
 type Message struct {
-	ID               uuid.UUID
-	ChatID           uuid.UUID
-	UserID           uuid.UUID
-	Text             string
-	IsEdited         bool
-	ReplyToMessageID *uuid.UUID
-	CreatedAt        time.Time
-	UpdatedAt        time.Time
-	DeletedAt        gorm.DeletedAt
+	ID                     uuid.UUID
+	ChatID                 uuid.UUID
+	Seq                    int64
+	UserID                 uuid.UUID
+	Kind                   string `gorm:"default:user"`
+	Text                   string
+	Entities               []MessageEntity `gorm:"serializer:json"`
+	IsEdited               bool
+	ReplyToMessageID       *uuid.UUID
+	ForwardedFromMessageID *uuid.UUID // forwards of forwards point at the original message
+	ForwardedFromChatID    *uuid.UUID
+	ForwardedFromUserID    *uuid.UUID
+	ExpiresAt              *time.Time
+	CreatedAt              time.Time
+	UpdatedAt              time.Time
+	DeletedAt              gorm.DeletedAt
 
 	Chat           Chat
 	User           User
 	ReplyToMessage *Message
-	Replies        []Message
+	Replies        []Message `gorm:"foreignKey:ReplyToMessageID"`
+	Mentions       []MessageMention
+	Attachments    []Attachment
+	LinkPreviews   []LinkPreview   `gorm:"many2many:message_link_previews;joinForeignKey:MessageID;joinReferences:URL"`
+	Reactions      []ReactionCount `gorm:"-"`
+	Thread         ThreadSummary   `gorm:"-"`
+	// Format tells how Text was written when creating or editing the message; it is not stored
+	Format string `gorm:"-"`
+}
+
+// MessageTombstone stands in for an expired message once it is gone. It takes the next sequence number of the chat,
+// so clients replaying by sequence hear about the deletion even when they were already past the message.
+type MessageTombstone struct {
+	MessageID uuid.UUID `gorm:"primaryKey"`
+	ChatID    uuid.UUID
+	Seq       int64
+	UserID    uuid.UUID
+	Kind      string
+	CreatedAt time.Time
+	DeletedAt time.Time
+}
+
+type MessageCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+type MessagePage struct {
+	Before   *MessageCursor
+	After    *MessageCursor
+	Limit    int
+	ThreadID *uuid.UUID
 }
 
 type MessageRead struct {
@@ -36,3 +88,88 @@ type MessageRead struct {
 func (MessageRead) TableName() string {
 	return "message_reads"
 }
+
+const (
+	MessageStatusSent      = "sent"
+	MessageStatusDelivered = "delivered"
+	MessageStatusRead      = "read"
+)
+
+type MessageDelivery struct {
+	MessageID   uuid.UUID
+	UserID      uuid.UUID
+	DeliveredAt time.Time
+
+	Message Message
+	User    User
+}
+
+func (MessageDelivery) TableName() string {
+	return "message_deliveries"
+}
+
+type MessageReceipt struct {
+	UserID      uuid.UUID
+	Status      string
+	DeliveredAt *time.Time
+	ReadAt      *time.Time
+}
+
+type MessageStatus struct {
+	MessageID      uuid.UUID
+	Status         string
+	DeliveredCount int
+	ReadCount      int
+	Receipts       []MessageReceipt
+}
+
+type MessageReaction struct {
+	MessageID uuid.UUID
+	UserID    uuid.UUID
+	Emoji     string
+	CreatedAt time.Time
+
+	Message Message
+	User    User
+}
+
+func (MessageReaction) TableName() string {
+	return "message_reactions"
+}
+
+type PinnedMessage struct {
+	MessageID uuid.UUID
+	ChatID    uuid.UUID
+	PinnedBy  *uuid.UUID
+	PinnedAt  time.Time
+
+	Message Message
+}
+
+func (PinnedMessage) TableName() string {
+	return "pinned_messages"
+}
+
+type ReactionCount struct {
+	MessageID uuid.UUID
+	Emoji     string
+	Count     int
+	Reacted   bool
+}
+
+type MessageEdit struct {
+	ID        uuid.UUID
+	MessageID uuid.UUID
+	Text      string
+	Entities  []MessageEntity `gorm:"serializer:json"`
+	WrittenAt time.Time
+	EditedAt  time.Time
+
+	Message Message
+}
+
+type ThreadSummary struct {
+	MessageID   uuid.UUID
+	ReplyCount  int
+	LastReplyAt *time.Time
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	PresenceOnline  = "online"
+	PresenceAway    = "away"
+	PresenceOffline = "offline"
+)
+
+type Presence struct {
+	UserID     uuid.UUID
+	Status     string
+	LastSeenAt *time.Time
+}
+
+// PresenceConnection is a realtime connection of a user. The instance serving it keeps SeenAt fresh,
+// so the connections of an instance that went away stop counting on their own.
+type PresenceConnection struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	Away   bool
+	SeenAt time.Time
+}
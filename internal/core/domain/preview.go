@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"time"
+)
+
+const (
+	LinkPreviewPending = "pending"
+	LinkPreviewReady   = "ready"
+	LinkPreviewFailed  = "failed"
+)
+
+// LinkPreview caches the metadata of a web page, shared by every message linking to it
+type LinkPreview struct {
+	URL         string `gorm:"primaryKey"`
+	Status      string `gorm:"default:pending"`
+	Title       string
+	Description string
+	ImageURL    string
+	SiteName    string
+	FetchedAt   *time.Time
+	CreatedAt   time.Time
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ScheduledStatusPending  = "pending"
+	ScheduledStatusSending  = "sending"
+	ScheduledStatusSent     = "sent"
+	ScheduledStatusFailed   = "failed"
+	ScheduledStatusCanceled = "canceled"
+)
+
+// ScheduledMessage is a message written now and sent later. Once sent, the message takes the same id,
+// which keeps a dispatch retried after a crash from sending it twice.
+type ScheduledMessage struct {
+	ID     uuid.UUID
+	ChatID uuid.UUID
+	UserID uuid.UUID
+	Text   string
+	// Format tells how Text was written; the text is kept as written and formatted when the message is sent
+	Format           string `gorm:"default:plain"`
+	ReplyToMessageID *uuid.UUID
+	SendAt           time.Time
+	Status           string `gorm:"default:pending"`
+	FailureReason    string
+	ClaimedAt        *time.Time
+	SentAt           *time.Time
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MessageSearch is a full-text search over the messages of the chats a user takes part in, best matches first
+type MessageSearch struct {
+	Query         string
+	ChatID        *uuid.UUID
+	SenderID      *uuid.UUID
+	From          *time.Time
+	To            *time.Time
+	HasAttachment bool
+	After         *SearchCursor
+	Limit         int
+}
+
+// SearchCursor is the position of a result in the ranked order of a search
+type SearchCursor struct {
+	Rank      float32
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+type MessageSearchResult struct {
+	MessageID uuid.UUID
+	CreatedAt time.Time
+	Rank      float32
+	// Snippet is the matching part of the text, HTML escaped, with the matched words wrapped in <mark> tags
+	Snippet string
+
+	Message Message
+}
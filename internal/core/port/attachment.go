@@ -0,0 +1,28 @@
+package port
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+)
+
+type AttachmentRepository interface {
+	CreateAttachment(ctx context.Context, attachment *domain.Attachment) (*domain.Attachment, error)
+	GetAttachmentByID(ctx context.Context, id string) (*domain.Attachment, error)
+	// Thumbnails
+	ClaimPendingThumbnail(ctx context.Context, staleAfter time.Duration) (*domain.Attachment, error)
+	SaveAttachmentThumbnails(ctx context.Context, attachment *domain.Attachment) error
+	UpdateAttachmentThumbnailStatus(ctx context.Context, id, status string) error
+}
+
+type AttachmentService interface {
+	// UploadAttachment stores the content read from r, checking its size and sniffed type against the configured limits
+	UploadAttachment(ctx context.Context, attachment *domain.Attachment, r io.Reader) (*domain.Attachment, error)
+	GetAttachment(ctx context.Context, id string) (*domain.Attachment, error)
+	OpenAttachment(ctx context.Context, attachment *domain.Attachment) (io.ReadCloser, error)
+	OpenThumbnail(ctx context.Context, thumbnail *domain.AttachmentThumbnail) (io.ReadCloser, error)
+	// RunThumbnailWorker generates the thumbnails of uploaded images in the background until ctx is done
+	RunThumbnailWorker(ctx context.Context) error
+}
@@ -0,0 +1,15 @@
+package port
+
+import (
+	"context"
+	"io"
+)
+
+// BlobStore keeps the content of uploaded files, addressed by opaque keys
+type BlobStore interface {
+	// Put stores the content read from r under key and returns how many bytes were written
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	// Get returns util.ErrDataNotFound when nothing is stored under key
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
@@ -2,17 +2,23 @@ package port
 
 import (
 	"context"
+	"time"
 
 	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/google/uuid"
 )
 
 type ChatRepository interface {
 	// Chats
 	CreateChat(ctx context.Context, chat *domain.Chat) (*domain.Chat, error)
+	GetOrCreateDirectChat(ctx context.Context, chat *domain.Chat) (*domain.Chat, error)
 	GetChatByID(ctx context.Context, id string) (*domain.Chat, error)
 	GetChatsByUserID(ctx context.Context, id string) ([]domain.Chat, error)
+	// GetContactIDsByUserID returns the users sharing at least one chat with the given user
+	GetContactIDsByUserID(ctx context.Context, id string) ([]uuid.UUID, error)
 	GetChats(ctx context.Context, skip uint64, limit uint64) ([]domain.Chat, error)
 	UpdateChat(ctx context.Context, chat *domain.Chat) (*domain.Chat, error)
+	UpdateChatMessageTTL(ctx context.Context, id string, ttl int) (*domain.Chat, error)
 	DeleteChat(ctx context.Context, id string) error
 	// ChatParticipants
 	CreateChatParticipant(ctx context.Context, chatParticipant *domain.ChatParticipant) (*domain.ChatParticipant, error)
@@ -20,20 +26,42 @@ type ChatRepository interface {
 	GetChatParticipantsByChatID(ctx context.Context, id string) ([]domain.ChatParticipant, error)
 	UpdateChatParticipant(ctx context.Context, chatParticipant *domain.ChatParticipant) (*domain.ChatParticipant, error)
 	DeleteChatParticipant(ctx context.Context, chatID, userID string) error
+	// ChatDrafts
+	SaveChatDraft(ctx context.Context, draft *domain.ChatDraft) (*domain.ChatDraft, error)
+	GetChatDraftsByUserID(ctx context.Context, userID string) ([]domain.ChatDraft, error)
+	// DeleteChatDraft returns util.ErrDataNotFound if the user had no draft in the chat
+	DeleteChatDraft(ctx context.Context, chatID, userID string) error
 }
 
 type ChatService interface {
 	// Chats
 	CreateChat(ctx context.Context, chat *domain.Chat) (*domain.Chat, error)
+	GetOrCreateDirectChat(ctx context.Context, userID, peerID string) (*domain.Chat, error)
 	GetChatByID(ctx context.Context, id string) (*domain.Chat, error)
+	// GetChatsByUserID returns the chats of the user along with their drafts
 	GetChatsByUserID(ctx context.Context, id string) ([]domain.Chat, error)
 	GetChats(ctx context.Context, skip uint64, limit uint64) ([]domain.Chat, error)
-	UpdateChat(ctx context.Context, chat *domain.Chat) (*domain.Chat, error)
-	DeleteChat(ctx context.Context, id string) error
+	// UpdateChat requires the actor to be at least a moderator of the chat
+	UpdateChat(ctx context.Context, actorID string, chat *domain.Chat) (*domain.Chat, error)
+	// DeleteChat requires the actor to be an admin of the chat
+	DeleteChat(ctx context.Context, actorID, id string) error
 	// ChatParticipants
 	CreateChatParticipant(ctx context.Context, chatParticipant *domain.ChatParticipant) (*domain.ChatParticipant, error)
 	GetChatParticipantByChatIDUserID(ctx context.Context, chatID, userID string) (*domain.ChatParticipant, error)
 	GetChatParticipantsByChatID(ctx context.Context, id string) ([]domain.ChatParticipant, error)
 	UpdateChatParticipant(ctx context.Context, chatParticipant *domain.ChatParticipant) (*domain.ChatParticipant, error)
 	DeleteChatParticipant(ctx context.Context, chatID, userID string) error
+	// ChatDrafts
+	// SaveChatDraft clears the draft when it has neither text nor reply target, returning nil
+	SaveChatDraft(ctx context.Context, draft *domain.ChatDraft) (*domain.ChatDraft, error)
+	ClearChatDraft(ctx context.Context, chatID, userID string) error
+	// Membership with role checks
+	AddChatParticipant(ctx context.Context, actorID, chatID, userID string) (*domain.ChatParticipant, error)
+	RemoveChatParticipant(ctx context.Context, actorID, chatID, userID string) error
+	LeaveChat(ctx context.Context, chatID, userID string) error
+	PromoteChatParticipant(ctx context.Context, actorID, chatID, userID string) (*domain.ChatParticipant, error)
+	DemoteChatParticipant(ctx context.Context, actorID, chatID, userID string) (*domain.ChatParticipant, error)
+	// SetMessageTTL makes the messages sent from now on disappear after ttl, or keeps them when ttl is zero,
+	// and posts a notice in the chat
+	SetMessageTTL(ctx context.Context, actorID, chatID string, ttl time.Duration) (*domain.Chat, error)
 }
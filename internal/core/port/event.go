@@ -0,0 +1,22 @@
+package port
+
+import (
+	"context"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+)
+
+type EventPublisher interface {
+	Publish(ctx context.Context, event *domain.Event) error
+}
+
+// SignalPublisher carries short-lived events such as typing indicators, which subscribers receive like any other
+// event but without an id; signals are never numbered nor replayed
+type SignalPublisher interface {
+	PublishSignal(ctx context.Context, event *domain.Event) error
+}
+
+type EventSubscriber interface {
+	// Subscribe delivers every published event until ctx is done, then closes the channel
+	Subscribe(ctx context.Context) (<-chan domain.Event, error)
+}
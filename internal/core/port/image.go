@@ -0,0 +1,13 @@
+package port
+
+import (
+	"io"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+)
+
+type ImageProcessor interface {
+	// Thumbnails decodes the image read from r and returns its dimensions along with an encoded copy
+	// fitting each of the square bounding boxes; images are never scaled up
+	Thumbnails(r io.Reader, boxes []int) (width, height int, thumbnails []domain.AttachmentThumbnail, err error)
+}
@@ -4,30 +4,93 @@ import (
 	"context"
 
 	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/google/uuid"
 )
 
 type MessageRepository interface {
 	// Message
 	CreateMessage(ctx context.Context, message *domain.Message) (*domain.Message, error)
+	// CreateMessages saves the messages in a single transaction, so either all of them are created or none
+	CreateMessages(ctx context.Context, messages []*domain.Message) error
 	GetMessageByID(ctx context.Context, id string) (*domain.Message, error)
-	GetMessagesByChatID(ctx context.Context, chatID string) ([]domain.Message, error)
+	// GetMessagesByIDs returns the messages of the chat among ids in the order they were sent, skipping the missing ones
+	GetMessagesByIDs(ctx context.Context, chatID string, ids []uuid.UUID) ([]domain.Message, error)
+	GetMessagesByChatID(ctx context.Context, chatID string, page domain.MessagePage) ([]domain.Message, error)
+	// GetMessagesAfterSeq includes deleted messages so that replaying clients see no gaps; expired messages come back
+	// as deleted ones under a new sequence number once they are gone
+	GetMessagesAfterSeq(ctx context.Context, chatID string, afterSeq int64, limit int) ([]domain.Message, error)
+	// UpdateMessage keeps the replaced text as a domain.MessageEdit and links the message to the previews of its new links
 	UpdateMessage(ctx context.Context, message *domain.Message) (*domain.Message, error)
 	DeleteMessage(ctx context.Context, id string) error
+	GetMessageEditsByMessageID(ctx context.Context, id string) ([]domain.MessageEdit, error)
+	// DeleteExpiredMessages hard deletes up to limit expired messages and returns them with the storage keys
+	// of the files nothing refers to anymore
+	DeleteExpiredMessages(ctx context.Context, limit int) (messages []domain.Message, unusedKeys []string, err error)
 	// MessageRead
 	CreateMessageRead(ctx context.Context, messageRead *domain.MessageRead) (*domain.MessageRead, error)
 	GetMessageReadsByMessageID(ctx context.Context, id string) ([]domain.MessageRead, error)
 	DeleteMessageRead(ctx context.Context, messageID, userID string) error
+	// Receipts
+	MarkMessagesDelivered(ctx context.Context, upTo *domain.Message, userID string) (int64, error)
+	MarkMessagesRead(ctx context.Context, upTo *domain.Message, userID string) (int64, error)
+	GetMessageReceipts(ctx context.Context, messageID string) ([]domain.MessageReceipt, error)
+	// Reactions
+	CreateMessageReaction(ctx context.Context, reaction *domain.MessageReaction) (*domain.MessageReaction, error)
+	DeleteMessageReaction(ctx context.Context, reaction *domain.MessageReaction) error
+	GetReactionCounts(ctx context.Context, messageIDs []uuid.UUID, userID string) ([]domain.ReactionCount, error)
+	// Threads
+	GetThreadSummaries(ctx context.Context, messageIDs []uuid.UUID) ([]domain.ThreadSummary, error)
+	// Pins
+	CreatePinnedMessage(ctx context.Context, pin *domain.PinnedMessage, maxPins int) (*domain.PinnedMessage, error)
+	GetPinnedMessagesByChatID(ctx context.Context, chatID string) ([]domain.PinnedMessage, error)
+	DeletePinnedMessage(ctx context.Context, messageID string) error
+	// Mentions
+	GetMentionsByUserID(ctx context.Context, userID string, page domain.MentionPage) ([]domain.MessageMention, error)
+	// Search
+	SearchMessages(ctx context.Context, userID string, search domain.MessageSearch) ([]domain.MessageSearchResult, error)
 }
 
 type MessageService interface {
 	// Message
 	CreateMessage(ctx context.Context, message *domain.Message) (*domain.Message, error)
 	GetMessage(ctx context.Context, id string) (*domain.Message, error)
-	GetMessagesByChatID(ctx context.Context, chatID string) ([]domain.Message, error)
+	GetMessagesByChatID(ctx context.Context, chatID string, page domain.MessagePage) (messages []domain.Message, hasMore bool, err error)
+	GetMessagesAfterSeq(ctx context.Context, chatID string, afterSeq int64, limit int) (messages []domain.Message, hasMore bool, err error)
 	UpdateMessage(ctx context.Context, message *domain.Message) (*domain.Message, error)
 	DeleteMessage(ctx context.Context, id string) error
+	GetMessageEdits(ctx context.Context, id string) ([]domain.MessageEdit, error)
+	// RenderMessage returns the text in domain.MessageFormatHTML or domain.MessageFormatPlain
+	RenderMessage(message *domain.Message, format string) (string, error)
+	// Forwarding
+	ForwardMessages(ctx context.Context, userID, chatID string, messageIDs, targetChatIDs []uuid.UUID) ([]domain.Message, error)
 	// MessageRead
 	CreateMessageRead(ctx context.Context, messageRead *domain.MessageRead) (*domain.MessageRead, error)
 	GetMessageReadsByMessageID(ctx context.Context, id string) ([]domain.MessageRead, error)
 	DeleteMessageRead(ctx context.Context, messageID, userID string) error
+	// Receipts
+	MarkDelivered(ctx context.Context, message *domain.Message, userID string) error
+	MarkRead(ctx context.Context, message *domain.Message, userID string) error
+	GetMessageStatus(ctx context.Context, message *domain.Message) (*domain.MessageStatus, error)
+	// Reactions
+	AddReaction(ctx context.Context, message *domain.Message, userID, emoji string) ([]domain.ReactionCount, error)
+	RemoveReaction(ctx context.Context, message *domain.Message, userID, emoji string) ([]domain.ReactionCount, error)
+	// LoadReactions fills in the aggregated reactions of the messages as seen by the user
+	LoadReactions(ctx context.Context, messages []domain.Message, userID string) error
+	// Threads
+	GetThreadReplies(ctx context.Context, root *domain.Message, page domain.MessagePage) (replies []domain.Message, hasMore bool, err error)
+	// LoadThreadSummaries fills in the reply count and last reply time of the messages
+	LoadThreadSummaries(ctx context.Context, messages []domain.Message) error
+	// Pins
+	PinMessage(ctx context.Context, message *domain.Message, userID string) (*domain.PinnedMessage, error)
+	UnpinMessage(ctx context.Context, message *domain.Message, userID string) error
+	GetPinnedMessages(ctx context.Context, chatID string) ([]domain.PinnedMessage, error)
+	// Mentions
+	GetMentions(ctx context.Context, userID string, page domain.MentionPage) (mentions []domain.MessageMention, hasMore bool, err error)
+	// Search
+	SearchMessages(ctx context.Context, userID string, search domain.MessageSearch) (results []domain.MessageSearchResult, hasMore bool, err error)
+}
+
+type ExpiryService interface {
+	// Run deletes the messages of disappearing chats once they expire, until ctx is done
+	Run(ctx context.Context) error
 }
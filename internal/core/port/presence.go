@@ -0,0 +1,33 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+type PresenceRepository interface {
+	// SavePresenceConnection creates or updates the connection and returns the live connections of its user before and after
+	SavePresenceConnection(ctx context.Context, connection *domain.PresenceConnection, staleAfter time.Duration) (before, after []domain.PresenceConnection, err error)
+	// DeletePresenceConnection returns the live connections of the user before and after the connection is gone
+	DeletePresenceConnection(ctx context.Context, userID, id string, staleAfter time.Duration) (before, after []domain.PresenceConnection, err error)
+	// GetPresenceConnectionsByUserIDs returns the connections of the users seen within staleAfter
+	GetPresenceConnectionsByUserIDs(ctx context.Context, userIDs []uuid.UUID, staleAfter time.Duration) ([]domain.PresenceConnection, error)
+	// TouchPresenceConnections marks the connections as seen and returns those that still existed
+	TouchPresenceConnections(ctx context.Context, ids []uuid.UUID, seenAt time.Time) ([]domain.PresenceConnection, error)
+	// DeleteStalePresenceConnections removes and returns the connections not seen within staleAfter
+	DeleteStalePresenceConnections(ctx context.Context, staleAfter time.Duration) ([]domain.PresenceConnection, error)
+}
+
+type PresenceService interface {
+	// Connect registers a new connection of the user and returns its id
+	Connect(ctx context.Context, userID string) (string, error)
+	SetAway(ctx context.Context, userID, connectionID string, away bool) error
+	Disconnect(ctx context.Context, userID, connectionID string) error
+	// GetStatuses returns the status of every given user, across all instances
+	GetStatuses(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]string, error)
+	// Run keeps the connections of this instance alive and sweeps those of instances that went away, until ctx is done
+	Run(ctx context.Context) error
+}
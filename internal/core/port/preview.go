@@ -0,0 +1,25 @@
+package port
+
+import (
+	"context"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+)
+
+type LinkPreviewFetcher interface {
+	// Fetch reads the OpenGraph and Twitter card metadata of the page
+	Fetch(ctx context.Context, url string) (*domain.LinkPreview, error)
+}
+
+type LinkPreviewRepository interface {
+	UpdateLinkPreview(ctx context.Context, preview *domain.LinkPreview) error
+}
+
+type LinkPreviewService interface {
+	// ExtractLinkPreviews fills in the previews of the links found in the text and link entities of a message about to be created or edited
+	ExtractLinkPreviews(message *domain.Message)
+	// ScheduleLinkPreviews queues the previews of a created message that are missing or outdated
+	ScheduleLinkPreviews(message *domain.Message)
+	// Run fetches the queued previews until ctx is done
+	Run(ctx context.Context) error
+}
@@ -0,0 +1,32 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+)
+
+type ScheduledMessageRepository interface {
+	CreateScheduledMessage(ctx context.Context, scheduled *domain.ScheduledMessage) (*domain.ScheduledMessage, error)
+	GetScheduledMessageByID(ctx context.Context, id string) (*domain.ScheduledMessage, error)
+	// GetScheduledMessagesByChatIDUserID returns the scheduled messages of the user in the chat that were not sent nor canceled
+	GetScheduledMessagesByChatIDUserID(ctx context.Context, chatID, userID string) ([]domain.ScheduledMessage, error)
+	// UpdatePendingScheduledMessage returns util.ErrScheduledMessageNotPending once the message left the pending state
+	UpdatePendingScheduledMessage(ctx context.Context, scheduled *domain.ScheduledMessage) (*domain.ScheduledMessage, error)
+	// Dispatching
+	ClaimDueScheduledMessages(ctx context.Context, limit int, staleAfter time.Duration) ([]domain.ScheduledMessage, error)
+	MessageExists(ctx context.Context, id string) (bool, error)
+	UpdateScheduledMessageStatus(ctx context.Context, id, status, reason string) error
+}
+
+type ScheduledMessageService interface {
+	ScheduleMessage(ctx context.Context, scheduled *domain.ScheduledMessage) (*domain.ScheduledMessage, error)
+	GetScheduledMessage(ctx context.Context, id string) (*domain.ScheduledMessage, error)
+	GetScheduledMessages(ctx context.Context, chatID, userID string) ([]domain.ScheduledMessage, error)
+	// UpdateScheduledMessage changes the text or the send time of a pending scheduled message
+	UpdateScheduledMessage(ctx context.Context, scheduled *domain.ScheduledMessage) (*domain.ScheduledMessage, error)
+	CancelScheduledMessage(ctx context.Context, scheduled *domain.ScheduledMessage) error
+	// RunDispatcher sends the scheduled messages once they are due, until ctx is done
+	RunDispatcher(ctx context.Context) error
+}
@@ -0,0 +1,8 @@
+package port
+
+import "context"
+
+type TypingService interface {
+	StartTyping(ctx context.Context, chatID, userID string) error
+	StopTyping(ctx context.Context, chatID, userID string) error
+}
@@ -2,6 +2,7 @@ package port
 
 import (
 	"context"
+	"time"
 
 	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
 )
@@ -12,6 +13,7 @@ type UserRepository interface {
 	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
 	GetUsers(ctx context.Context, skip uint64, limit uint64) ([]domain.User, error)
 	UpdateUser(ctx context.Context, user *domain.User) (*domain.User, error)
+	UpdateUserLastSeen(ctx context.Context, id string, lastSeenAt time.Time) error
 	DeleteUser(ctx context.Context, id string) error
 }
 
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/HellEaglee/Golang-Chat/internal/core/port"
+	"github.com/HellEaglee/Golang-Chat/internal/core/util"
+	"github.com/google/uuid"
+)
+
+const (
+	// sniffLength is how much of the content is inspected to tell its type
+	sniffLength = 512
+	// maxFileNameLength matches the file_name column
+	maxFileNameLength = 255
+	// thumbnailPollInterval is how often the worker looks for images uploaded through other instances
+	thumbnailPollInterval = 30 * time.Second
+	// thumbnailStaleAfter is how long an image can stay claimed before another worker takes it over
+	thumbnailStaleAfter = 5 * time.Minute
+)
+
+// thumbnailSizes are the bounding boxes, in pixels, of the thumbnails generated for each image
+var thumbnailSizes = []struct {
+	name string
+	box  int
+}{
+	{name: "small", box: 160},
+	{name: "medium", box: 480},
+}
+
+// thumbnailTypes are the image types the worker can decode
+var thumbnailTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+type AttachmentService struct {
+	repo      port.AttachmentRepository
+	blobs     port.BlobStore
+	images    port.ImageProcessor
+	publisher port.EventPublisher
+	// wake lets uploads on this instance start the thumbnail worker right away
+	wake chan struct{}
+	// maxSize is the largest accepted upload in bytes
+	maxSize int64
+	// allowedTypes lists the accepted media types, "image/*" style wildcards included; empty accepts anything
+	allowedTypes []string
+}
+
+func NewAttachmentService(repo port.AttachmentRepository, blobs port.BlobStore, images port.ImageProcessor,
+	publisher port.EventPublisher, maxSize int64, allowedTypes []string,
+) *AttachmentService {
+	return &AttachmentService{
+		repo:         repo,
+		blobs:        blobs,
+		images:       images,
+		publisher:    publisher,
+		wake:         make(chan struct{}, 1),
+		maxSize:      maxSize,
+		allowedTypes: allowedTypes,
+	}
+}
+
+// UploadAttachment trusts neither the declared type nor the declared size of the file:
+// the type is sniffed from the content and the size is counted while storing it
+func (s *AttachmentService) UploadAttachment(ctx context.Context, attachment *domain.Attachment, r io.Reader) (*domain.Attachment, error) {
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	head = head[:n]
+
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil || !s.allowedType(contentType) {
+		return nil, util.ErrUnsupportedFileType
+	}
+
+	key := uuid.New().String()
+	content := io.LimitReader(io.MultiReader(bytes.NewReader(head), r), s.maxSize+1)
+	size, err := s.blobs.Put(ctx, key, content)
+	if err != nil {
+		return nil, err
+	}
+	if size > s.maxSize {
+		s.deleteBlob(ctx, key)
+		return nil, util.ErrFileTooLarge
+	}
+
+	attachment.ID = uuid.New()
+	attachment.FileName = cleanFileName(attachment.FileName)
+	attachment.ContentType = contentType
+	attachment.Size = size
+	attachment.StorageKey = key
+	attachment.ThumbnailStatus = domain.ThumbnailStatusNone
+	if slices.Contains(thumbnailTypes, contentType) {
+		attachment.ThumbnailStatus = domain.ThumbnailStatusPending
+	}
+
+	createdAttachment, err := s.repo.CreateAttachment(ctx, attachment)
+	if err != nil {
+		s.deleteBlob(ctx, key)
+		return nil, err
+	}
+
+	if createdAttachment.ThumbnailStatus == domain.ThumbnailStatusPending {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+	return createdAttachment, nil
+}
+
+func (s *AttachmentService) GetAttachment(ctx context.Context, id string) (*domain.Attachment, error) {
+	return s.repo.GetAttachmentByID(ctx, id)
+}
+
+func (s *AttachmentService) OpenAttachment(ctx context.Context, attachment *domain.Attachment) (io.ReadCloser, error) {
+	return s.blobs.Get(ctx, attachment.StorageKey)
+}
+
+func (s *AttachmentService) OpenThumbnail(ctx context.Context, thumbnail *domain.AttachmentThumbnail) (io.ReadCloser, error) {
+	return s.blobs.Get(ctx, thumbnail.StorageKey)
+}
+
+func (s *AttachmentService) allowedType(contentType string) bool {
+	if len(s.allowedTypes) == 0 {
+		return true
+	}
+	for _, allowed := range s.allowedTypes {
+		if allowed == contentType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok && strings.HasPrefix(contentType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *AttachmentService) deleteBlob(ctx context.Context, key string) {
+	if err := s.blobs.Delete(ctx, key); err != nil {
+		slog.Error("Error deleting blob", "key", key, "error", err)
+	}
+}
+
+// cleanFileName keeps the base name the client sent, trimmed to fit the column
+func cleanFileName(name string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "." || name == "/" || name == "" {
+		return "file"
+	}
+	for len(name) > maxFileNameLength {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
+
+// ----------------------------------------------------THUMBNAILS----------------------------------------------------
+// RunThumbnailWorker drains the queue of images waiting for thumbnails whenever an upload comes in,
+// and regularly in case they were uploaded through another instance
+func (s *AttachmentService) RunThumbnailWorker(ctx context.Context) error {
+	ticker := time.NewTicker(thumbnailPollInterval)
+	defer ticker.Stop()
+
+	for {
+		for s.processNextThumbnail(ctx) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// processNextThumbnail reports whether an image was taken from the queue
+func (s *AttachmentService) processNextThumbnail(ctx context.Context) bool {
+	attachment, err := s.repo.ClaimPendingThumbnail(ctx, thumbnailStaleAfter)
+	if err != nil {
+		if err != util.ErrDataNotFound && ctx.Err() == nil {
+			slog.Error("Error claiming an image for thumbnails", "error", err)
+		}
+		return false
+	}
+
+	if err := s.generateThumbnails(ctx, attachment); err != nil {
+		slog.Warn("Error generating thumbnails", "attachment_id", attachment.ID, "error", err)
+		if err := s.repo.UpdateAttachmentThumbnailStatus(ctx, attachment.ID.String(), domain.ThumbnailStatusFailed); err != nil {
+			slog.Error("Error updating thumbnail status", "attachment_id", attachment.ID, "error", err)
+		}
+		return true
+	}
+
+	// unsent uploads only matter to their uploader, who learns about them with the message
+	if attachment.MessageID != nil {
+		event := domain.NewEvent(domain.EventAttachmentUpdated, attachment.ChatID, attachment.UserID)
+		event.Attachment = attachment
+		publishEvent(ctx, s.publisher, event)
+	}
+	return true
+}
+
+func (s *AttachmentService) generateThumbnails(ctx context.Context, attachment *domain.Attachment) error {
+	content, err := s.blobs.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	boxes := make([]int, len(thumbnailSizes))
+	for i, size := range thumbnailSizes {
+		boxes[i] = size.box
+	}
+
+	width, height, thumbnails, err := s.images.Thumbnails(content, boxes)
+	if err != nil {
+		return err
+	}
+
+	for i := range thumbnails {
+		thumbnail := &thumbnails[i]
+		thumbnail.AttachmentID = attachment.ID
+		thumbnail.Size = thumbnailSizes[i].name
+		thumbnail.StorageKey = attachment.StorageKey + "-" + thumbnail.Size
+		if _, err := s.blobs.Put(ctx, thumbnail.StorageKey, bytes.NewReader(thumbnail.Data)); err != nil {
+			return err
+		}
+		thumbnail.Data = nil
+	}
+
+	attachment.Width, attachment.Height = &width, &height
+	attachment.Thumbnails = thumbnails
+	if err := s.repo.SaveAttachmentThumbnails(ctx, attachment); err != nil {
+		return err
+	}
+	attachment.ThumbnailStatus = domain.ThumbnailStatusReady
+	return nil
+}
@@ -2,22 +2,101 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"time"
 
 	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
 	"github.com/HellEaglee/Golang-Chat/internal/core/port"
+	"github.com/HellEaglee/Golang-Chat/internal/core/util"
+	"github.com/google/uuid"
+)
+
+// chatRoleLadder orders participant roles from the least to the most privileged
+var chatRoleLadder = []string{domain.ChatRoleMember, domain.ChatRoleModerator, domain.ChatRoleAdmin}
+
+const (
+	minMessageTTL = time.Minute
+	maxMessageTTL = 365 * 24 * time.Hour
 )
 
 type ChatService struct {
-	repo port.ChatRepository
+	repo        port.ChatRepository
+	userRepo    port.UserRepository
+	messageRepo port.MessageRepository
+	publisher   port.EventPublisher
 }
 
-func NewChatService(repo port.ChatRepository) *ChatService {
-	return &ChatService{repo: repo}
+func NewChatService(repo port.ChatRepository, userRepo port.UserRepository, messageRepo port.MessageRepository, publisher port.EventPublisher) *ChatService {
+	return &ChatService{repo: repo, userRepo: userRepo, messageRepo: messageRepo, publisher: publisher}
 }
 
 // ----------------------------------------------------CHATS----------------------------------------------------
+// CreateChat creates a group chat; non-group chats are resolved through GetOrCreateDirectChat so a pair never has two of them
 func (s *ChatService) CreateChat(ctx context.Context, chat *domain.Chat) (*domain.Chat, error) {
-	return s.repo.CreateChat(ctx, chat)
+	if !chat.IsGroup {
+		if len(chat.Participants) != 2 {
+			return nil, util.ErrInvalidDirectChat
+		}
+		return s.GetOrCreateDirectChat(ctx, chat.Participants[0].UserID.String(), chat.Participants[1].UserID.String())
+	}
+
+	createdChat, err := s.repo.CreateChat(ctx, chat)
+	if err != nil {
+		return nil, err
+	}
+
+	event := domain.NewEvent(domain.EventChatCreated, createdChat.ID, createdChat.Participants[0].UserID)
+	event.Chat = createdChat
+	publishEvent(ctx, s.publisher, event)
+
+	return createdChat, nil
+}
+
+func (s *ChatService) GetOrCreateDirectChat(ctx context.Context, userID, peerID string) (*domain.Chat, error) {
+	if userID == peerID {
+		return nil, util.ErrInvalidDirectChat
+	}
+
+	peer, err := s.userRepo.GetUserByID(ctx, peerID)
+	if err != nil {
+		return nil, err
+	}
+
+	user := uuid.MustParse(userID)
+	directKey := directChatKey(user, peer.ID)
+	chat := &domain.Chat{
+		ID:        uuid.New(),
+		IsGroup:   false,
+		DirectKey: &directKey,
+		Participants: []domain.ChatParticipant{
+			{UserID: user, Role: domain.ChatRoleMember},
+			{UserID: peer.ID, Role: domain.ChatRoleMember},
+		},
+	}
+	directChat, err := s.repo.GetOrCreateDirectChat(ctx, chat)
+	if err != nil {
+		return nil, err
+	}
+
+	// the repository hands back an older chat when the pair already had one
+	if directChat.ID == chat.ID {
+		directChat.Participants = chat.Participants
+		event := domain.NewEvent(domain.EventChatCreated, directChat.ID, user)
+		event.Chat = directChat
+		publishEvent(ctx, s.publisher, event)
+	}
+
+	return directChat, nil
+}
+
+// directChatKey builds the order-independent key identifying the direct chat of two users
+func directChatKey(a, b uuid.UUID) string {
+	first, second := a.String(), b.String()
+	if second < first {
+		first, second = second, first
+	}
+	return first + ":" + second
 }
 
 func (s *ChatService) GetChatByID(ctx context.Context, id string) (*domain.Chat, error) {
@@ -25,19 +104,68 @@ func (s *ChatService) GetChatByID(ctx context.Context, id string) (*domain.Chat,
 }
 
 func (s *ChatService) GetChatsByUserID(ctx context.Context, id string) ([]domain.Chat, error) {
-	return s.repo.GetChatsByUserID(ctx, id)
+	chats, err := s.repo.GetChatsByUserID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	drafts, err := s.repo.GetChatDraftsByUserID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for i := range drafts {
+		for j := range chats {
+			if chats[j].ID == drafts[i].ChatID {
+				chats[j].Draft = &drafts[i]
+				break
+			}
+		}
+	}
+	return chats, nil
 }
 
 func (s *ChatService) GetChats(ctx context.Context, skip uint64, limit uint64) ([]domain.Chat, error) {
 	return s.repo.GetChats(ctx, skip, limit)
 }
 
-func (s *ChatService) UpdateChat(ctx context.Context, chat *domain.Chat) (*domain.Chat, error) {
-	return s.repo.UpdateChat(ctx, chat)
+// UpdateChat changes the details of the chat; only moderators and admins may do so
+func (s *ChatService) UpdateChat(ctx context.Context, actorID string, chat *domain.Chat) (*domain.Chat, error) {
+	actor, err := s.getActor(ctx, chat.ID.String(), actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !hasChatRole(actor, domain.ChatRoleModerator) {
+		return nil, util.ErrForbidden
+	}
+
+	updatedChat, err := s.repo.UpdateChat(ctx, chat)
+	if err != nil {
+		return nil, err
+	}
+
+	event := domain.NewEvent(domain.EventChatUpdated, updatedChat.ID, actor.UserID)
+	event.Chat = updatedChat
+	publishEvent(ctx, s.publisher, event)
+
+	return updatedChat, nil
 }
 
-func (s *ChatService) DeleteChat(ctx context.Context, id string) error {
-	return s.repo.DeleteChat(ctx, id)
+// DeleteChat deletes the chat for everyone; only admins may do so
+func (s *ChatService) DeleteChat(ctx context.Context, actorID, id string) error {
+	actor, err := s.getActor(ctx, id, actorID)
+	if err != nil {
+		return err
+	}
+	if !hasChatRole(actor, domain.ChatRoleAdmin) {
+		return util.ErrForbidden
+	}
+
+	if err := s.repo.DeleteChat(ctx, id); err != nil {
+		return err
+	}
+
+	publishEvent(ctx, s.publisher, domain.NewEvent(domain.EventChatDeleted, uuid.MustParse(id), actor.UserID))
+	return nil
 }
 
 // ----------------------------------------------------CHAT_PARTICIPANTS----------------------------------------------------
@@ -60,3 +188,326 @@ func (s *ChatService) UpdateChatParticipant(ctx context.Context, chatParticipant
 func (s *ChatService) DeleteChatParticipant(ctx context.Context, chatID, userID string) error {
 	return s.repo.DeleteChatParticipant(ctx, chatID, userID)
 }
+
+// ----------------------------------------------------CHAT_DRAFTS----------------------------------------------------
+// SaveChatDraft keeps what the user is writing in the chat and shows it on their other devices
+func (s *ChatService) SaveChatDraft(ctx context.Context, draft *domain.ChatDraft) (*domain.ChatDraft, error) {
+	if draft.Text == "" && draft.ReplyToMessageID == nil {
+		return nil, s.ClearChatDraft(ctx, draft.ChatID.String(), draft.UserID.String())
+	}
+
+	if draft.ReplyToMessageID != nil {
+		parent, err := s.messageRepo.GetMessageByID(ctx, draft.ReplyToMessageID.String())
+		if err != nil && err != util.ErrDataNotFound {
+			return nil, err
+		}
+		if parent == nil || parent.ChatID != draft.ChatID {
+			return nil, util.ErrInvalidReply
+		}
+	}
+
+	savedDraft, err := s.repo.SaveChatDraft(ctx, draft)
+	if err != nil {
+		return nil, err
+	}
+
+	event := domain.NewEvent(domain.EventDraftUpdated, savedDraft.ChatID, savedDraft.UserID)
+	event.Draft = savedDraft
+	publishEvent(ctx, s.publisher, event)
+
+	return savedDraft, nil
+}
+
+// ClearChatDraft drops the draft of the user in the chat, if they had one
+func (s *ChatService) ClearChatDraft(ctx context.Context, chatID, userID string) error {
+	if err := s.repo.DeleteChatDraft(ctx, chatID, userID); err != nil {
+		if err == util.ErrDataNotFound {
+			return nil
+		}
+		return err
+	}
+
+	publishEvent(ctx, s.publisher, domain.NewEvent(domain.EventDraftUpdated, uuid.MustParse(chatID), uuid.MustParse(userID)))
+	return nil
+}
+
+// ----------------------------------------------------MEMBERSHIP----------------------------------------------------
+// getActor returns the participant acting on the chat, or util.ErrForbidden if they are not part of it
+func (s *ChatService) getActor(ctx context.Context, chatID, actorID string) (*domain.ChatParticipant, error) {
+	actor, err := s.repo.GetChatParticipantByChatIDUserID(ctx, chatID, actorID)
+	if err != nil {
+		if err == util.ErrDataNotFound {
+			return nil, util.ErrForbidden
+		}
+		return nil, err
+	}
+	return actor, nil
+}
+
+// hasChatRole reports whether the participant holds the role or a more privileged one on chatRoleLadder
+func hasChatRole(participant *domain.ChatParticipant, role string) bool {
+	return slices.Index(chatRoleLadder, participant.Role) >= slices.Index(chatRoleLadder, role)
+}
+
+// countAdmins returns how many active participants the chat has and how many of them are admins
+func (s *ChatService) countAdmins(ctx context.Context, chatID string) (admins int, total int, err error) {
+	participants, err := s.repo.GetChatParticipantsByChatID(ctx, chatID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, participant := range participants {
+		if participant.Role == domain.ChatRoleAdmin {
+			admins++
+		}
+	}
+	return admins, len(participants), nil
+}
+
+func (s *ChatService) AddChatParticipant(ctx context.Context, actorID, chatID, userID string) (*domain.ChatParticipant, error) {
+	actor, err := s.getActor(ctx, chatID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if actor.Role != domain.ChatRoleAdmin && actor.Role != domain.ChatRoleModerator {
+		return nil, util.ErrForbidden
+	}
+
+	chat, err := s.repo.GetChatByID(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	if !chat.IsGroup {
+		return nil, util.ErrForbidden
+	}
+
+	_, err = s.repo.GetChatParticipantByChatIDUserID(ctx, chatID, userID)
+	if err == nil {
+		return nil, util.ErrConflictingData
+	}
+	if err != util.ErrDataNotFound {
+		return nil, err
+	}
+
+	participant := &domain.ChatParticipant{
+		ChatID: chat.ID,
+		UserID: uuid.MustParse(userID),
+		Role:   domain.ChatRoleMember,
+	}
+	createdParticipant, err := s.repo.CreateChatParticipant(ctx, participant)
+	if err != nil {
+		return nil, err
+	}
+
+	event := domain.NewEvent(domain.EventParticipantJoined, chat.ID, actor.UserID)
+	event.Participant = createdParticipant
+	publishEvent(ctx, s.publisher, event)
+
+	return createdParticipant, nil
+}
+
+// RemoveChatParticipant lets admins remove anyone and moderators remove plain members
+func (s *ChatService) RemoveChatParticipant(ctx context.Context, actorID, chatID, userID string) error {
+	if actorID == userID {
+		return s.LeaveChat(ctx, chatID, userID)
+	}
+
+	actor, err := s.getActor(ctx, chatID, actorID)
+	if err != nil {
+		return err
+	}
+
+	target, err := s.repo.GetChatParticipantByChatIDUserID(ctx, chatID, userID)
+	if err != nil {
+		return err
+	}
+
+	switch actor.Role {
+	case domain.ChatRoleAdmin:
+	case domain.ChatRoleModerator:
+		if target.Role != domain.ChatRoleMember {
+			return util.ErrForbidden
+		}
+	default:
+		return util.ErrForbidden
+	}
+
+	if err := s.repo.DeleteChatParticipant(ctx, chatID, userID); err != nil {
+		return err
+	}
+
+	event := domain.NewEvent(domain.EventParticipantLeft, target.ChatID, actor.UserID)
+	event.Participant = target
+	publishEvent(ctx, s.publisher, event)
+
+	return nil
+}
+
+// LeaveChat removes the user from the group chat unless they are the last admin while others remain.
+// Direct chats cannot be left, as nobody could add the user back to them.
+func (s *ChatService) LeaveChat(ctx context.Context, chatID, userID string) error {
+	participant, err := s.repo.GetChatParticipantByChatIDUserID(ctx, chatID, userID)
+	if err != nil {
+		return err
+	}
+
+	chat, err := s.repo.GetChatByID(ctx, chatID)
+	if err != nil {
+		return err
+	}
+	if !chat.IsGroup {
+		return util.ErrForbidden
+	}
+
+	if participant.Role == domain.ChatRoleAdmin {
+		admins, total, err := s.countAdmins(ctx, chatID)
+		if err != nil {
+			return err
+		}
+		if admins == 1 && total > 1 {
+			return util.ErrLastChatAdmin
+		}
+	}
+
+	if err := s.repo.DeleteChatParticipant(ctx, chatID, userID); err != nil {
+		return err
+	}
+
+	event := domain.NewEvent(domain.EventParticipantLeft, participant.ChatID, participant.UserID)
+	event.Participant = participant
+	publishEvent(ctx, s.publisher, event)
+
+	return nil
+}
+
+func (s *ChatService) PromoteChatParticipant(ctx context.Context, actorID, chatID, userID string) (*domain.ChatParticipant, error) {
+	return s.changeChatParticipantRole(ctx, actorID, chatID, userID, 1)
+}
+
+func (s *ChatService) DemoteChatParticipant(ctx context.Context, actorID, chatID, userID string) (*domain.ChatParticipant, error) {
+	return s.changeChatParticipantRole(ctx, actorID, chatID, userID, -1)
+}
+
+// changeChatParticipantRole moves the participant step positions along chatRoleLadder; only admins may do so
+func (s *ChatService) changeChatParticipantRole(ctx context.Context, actorID, chatID, userID string, step int) (*domain.ChatParticipant, error) {
+	actor, err := s.getActor(ctx, chatID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if actor.Role != domain.ChatRoleAdmin {
+		return nil, util.ErrForbidden
+	}
+
+	target, err := s.repo.GetChatParticipantByChatIDUserID(ctx, chatID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rank := slices.Index(chatRoleLadder, target.Role) + step
+	if rank < 0 || rank >= len(chatRoleLadder) {
+		return nil, util.ErrNoUpdatedData
+	}
+
+	if target.Role == domain.ChatRoleAdmin {
+		admins, _, err := s.countAdmins(ctx, chatID)
+		if err != nil {
+			return nil, err
+		}
+		if admins == 1 {
+			return nil, util.ErrLastChatAdmin
+		}
+	}
+
+	target.Role = chatRoleLadder[rank]
+	updatedParticipant, err := s.repo.UpdateChatParticipant(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	event := domain.NewEvent(domain.EventParticipantUpdated, updatedParticipant.ChatID, actor.UserID)
+	event.Participant = updatedParticipant
+	publishEvent(ctx, s.publisher, event)
+
+	return updatedParticipant, nil
+}
+
+// ----------------------------------------------------DISAPPEARING MESSAGES----------------------------------------------------
+// SetMessageTTL is open to both users of a direct chat and to the admins of a group chat.
+// Messages already sent keep the expiry they were given.
+func (s *ChatService) SetMessageTTL(ctx context.Context, actorID, chatID string, ttl time.Duration) (*domain.Chat, error) {
+	if ttl != 0 && (ttl < minMessageTTL || ttl > maxMessageTTL || ttl%time.Second != 0) {
+		return nil, util.ErrInvalidMessageTTL
+	}
+
+	actor, err := s.getActor(ctx, chatID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	chat, err := s.repo.GetChatByID(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	if chat.IsGroup && actor.Role != domain.ChatRoleAdmin {
+		return nil, util.ErrForbidden
+	}
+
+	seconds := int(ttl / time.Second)
+	if chat.MessageTTL == seconds {
+		return chat, nil
+	}
+
+	updatedChat, err := s.repo.UpdateChatMessageTTL(ctx, chatID, seconds)
+	if err != nil {
+		return nil, err
+	}
+
+	event := domain.NewEvent(domain.EventChatUpdated, updatedChat.ID, actor.UserID)
+	event.Chat = updatedChat
+	publishEvent(ctx, s.publisher, event)
+
+	notice := &domain.Message{
+		ID:     uuid.New(),
+		ChatID: updatedChat.ID,
+		UserID: actor.UserID,
+		Kind:   domain.MessageKindSystem,
+		Text:   messageTTLNotice(ttl),
+	}
+	createdNotice, err := s.messageRepo.CreateMessage(ctx, notice)
+	if err != nil {
+		return nil, err
+	}
+
+	event = domain.NewEvent(domain.EventMessageCreated, createdNotice.ChatID, createdNotice.UserID)
+	event.Message = createdNotice
+	publishEvent(ctx, s.publisher, event)
+
+	return updatedChat, nil
+}
+
+// messageTTLNotice words the TTL in the largest unit that divides it
+func messageTTLNotice(ttl time.Duration) string {
+	if ttl == 0 {
+		return "turned off disappearing messages"
+	}
+
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{name: "day", size: 24 * time.Hour},
+		{name: "hour", size: time.Hour},
+		{name: "minute", size: time.Minute},
+		{name: "second", size: time.Second},
+	}
+	for _, unit := range units {
+		if ttl%unit.size != 0 {
+			continue
+		}
+		count := ttl / unit.size
+		if count > 1 {
+			return fmt.Sprintf("set messages to disappear after %d %ss", count, unit.name)
+		}
+		return fmt.Sprintf("set messages to disappear after 1 %s", unit.name)
+	}
+	return ""
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/HellEaglee/Golang-Chat/internal/core/port"
+	"github.com/HellEaglee/Golang-Chat/internal/core/util"
+	"github.com/google/uuid"
+)
+
+// fakeChatRepository keeps the participants of a single chat in memory; methods the tests do not need panic
+type fakeChatRepository struct {
+	port.ChatRepository
+	participants []domain.ChatParticipant
+	updated      bool
+	deleted      bool
+	// directChats are the direct chats by their key
+	directChats map[string]*domain.Chat
+}
+
+func (r *fakeChatRepository) GetChatParticipantByChatIDUserID(ctx context.Context, chatID, userID string) (*domain.ChatParticipant, error) {
+	for _, participant := range r.participants {
+		if participant.ChatID.String() == chatID && participant.UserID.String() == userID {
+			return &participant, nil
+		}
+	}
+	return nil, util.ErrDataNotFound
+}
+
+func (r *fakeChatRepository) GetChatParticipantsByChatID(ctx context.Context, id string) ([]domain.ChatParticipant, error) {
+	return r.participants, nil
+}
+
+func (r *fakeChatRepository) UpdateChatParticipant(ctx context.Context, chatParticipant *domain.ChatParticipant) (*domain.ChatParticipant, error) {
+	return chatParticipant, nil
+}
+
+func (r *fakeChatRepository) DeleteChatParticipant(ctx context.Context, chatID, userID string) error {
+	return nil
+}
+
+func (r *fakeChatRepository) UpdateChat(ctx context.Context, chat *domain.Chat) (*domain.Chat, error) {
+	r.updated = true
+	return chat, nil
+}
+
+func (r *fakeChatRepository) DeleteChat(ctx context.Context, id string) error {
+	r.deleted = true
+	return nil
+}
+
+func (r *fakeChatRepository) GetOrCreateDirectChat(ctx context.Context, chat *domain.Chat) (*domain.Chat, error) {
+	if existing, ok := r.directChats[*chat.DirectKey]; ok {
+		return existing, nil
+	}
+	if r.directChats == nil {
+		r.directChats = make(map[string]*domain.Chat)
+	}
+	r.directChats[*chat.DirectKey] = chat
+	return chat, nil
+}
+
+type fakePublisher struct {
+	events []domain.Event
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, event *domain.Event) error {
+	p.events = append(p.events, *event)
+	return nil
+}
+
+// newChatFixture returns a chat service over a chat with one participant per given role, in order
+func newChatFixture(roles ...string) (*ChatService, *fakeChatRepository, uuid.UUID, []uuid.UUID) {
+	chatID := uuid.New()
+	repo := &fakeChatRepository{}
+	userIDs := make([]uuid.UUID, len(roles))
+	for i, role := range roles {
+		userIDs[i] = uuid.New()
+		repo.participants = append(repo.participants, domain.ChatParticipant{ChatID: chatID, UserID: userIDs[i], Role: role})
+	}
+	return NewChatService(repo, nil, nil, &fakePublisher{}), repo, chatID, userIDs
+}
+
+func TestHasChatRole(t *testing.T) {
+	tests := []struct {
+		role     string
+		required string
+		want     bool
+	}{
+		{domain.ChatRoleMember, domain.ChatRoleMember, true},
+		{domain.ChatRoleMember, domain.ChatRoleModerator, false},
+		{domain.ChatRoleMember, domain.ChatRoleAdmin, false},
+		{domain.ChatRoleModerator, domain.ChatRoleMember, true},
+		{domain.ChatRoleModerator, domain.ChatRoleModerator, true},
+		{domain.ChatRoleModerator, domain.ChatRoleAdmin, false},
+		{domain.ChatRoleAdmin, domain.ChatRoleModerator, true},
+		{domain.ChatRoleAdmin, domain.ChatRoleAdmin, true},
+		{"owner", domain.ChatRoleMember, false},
+		{"", domain.ChatRoleMember, false},
+	}
+
+	for _, tt := range tests {
+		participant := &domain.ChatParticipant{Role: tt.role}
+		if got := hasChatRole(participant, tt.required); got != tt.want {
+			t.Errorf("hasChatRole(%q, %q) = %v, want %v", tt.role, tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateAndDeleteChatRoles(t *testing.T) {
+	tests := []struct {
+		role       string
+		wantUpdate error
+		wantDelete error
+	}{
+		{role: domain.ChatRoleAdmin},
+		{role: domain.ChatRoleModerator, wantDelete: util.ErrForbidden},
+		{role: domain.ChatRoleMember, wantUpdate: util.ErrForbidden, wantDelete: util.ErrForbidden},
+		// an empty role stands for someone who is not in the chat at all
+		{role: "", wantUpdate: util.ErrForbidden, wantDelete: util.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			service, repo, chatID, userIDs := newChatFixture(domain.ChatRoleAdmin, tt.role)
+			actorID := userIDs[1].String()
+			if tt.role == "" {
+				actorID = uuid.NewString()
+			}
+
+			name := "renamed"
+			_, err := service.UpdateChat(context.Background(), actorID, &domain.Chat{ID: chatID, Name: &name})
+			if err != tt.wantUpdate {
+				t.Errorf("UpdateChat error = %v, want %v", err, tt.wantUpdate)
+			}
+			if repo.updated != (tt.wantUpdate == nil) {
+				t.Errorf("chat updated = %v", repo.updated)
+			}
+
+			err = service.DeleteChat(context.Background(), actorID, chatID.String())
+			if err != tt.wantDelete {
+				t.Errorf("DeleteChat error = %v, want %v", err, tt.wantDelete)
+			}
+			if repo.deleted != (tt.wantDelete == nil) {
+				t.Errorf("chat deleted = %v", repo.deleted)
+			}
+		})
+	}
+}
+
+func TestLastAdminGuard(t *testing.T) {
+	tests := []struct {
+		name      string
+		roles     []string
+		wantLeave error
+		// wantDemote is the outcome of the first admin demoting themselves
+		wantDemote error
+	}{
+		{
+			name:       "only admin with members",
+			roles:      []string{domain.ChatRoleAdmin, domain.ChatRoleMember, domain.ChatRoleModerator},
+			wantLeave:  util.ErrLastChatAdmin,
+			wantDemote: util.ErrLastChatAdmin,
+		},
+		{
+			name:       "only participant",
+			roles:      []string{domain.ChatRoleAdmin},
+			wantDemote: util.ErrLastChatAdmin,
+		},
+		{
+			name:  "two admins",
+			roles: []string{domain.ChatRoleAdmin, domain.ChatRoleAdmin, domain.ChatRoleMember},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _, chatID, userIDs := newChatFixture(tt.roles...)
+			adminID := userIDs[0].String()
+
+			if err := service.LeaveChat(context.Background(), chatID.String(), adminID); err != tt.wantLeave {
+				t.Errorf("LeaveChat error = %v, want %v", err, tt.wantLeave)
+			}
+			_, err := service.DemoteChatParticipant(context.Background(), adminID, chatID.String(), adminID)
+			if err != tt.wantDemote {
+				t.Errorf("DemoteChatParticipant error = %v, want %v", err, tt.wantDemote)
+			}
+		})
+	}
+}
+
+func TestChangeChatParticipantRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		actor    string
+		target   string
+		step     int
+		wantRole string
+		wantErr  error
+	}{
+		{name: "promote member", actor: domain.ChatRoleAdmin, target: domain.ChatRoleMember, step: 1, wantRole: domain.ChatRoleModerator},
+		{name: "promote moderator", actor: domain.ChatRoleAdmin, target: domain.ChatRoleModerator, step: 1, wantRole: domain.ChatRoleAdmin},
+		{name: "promote admin", actor: domain.ChatRoleAdmin, target: domain.ChatRoleAdmin, step: 1, wantErr: util.ErrNoUpdatedData},
+		{name: "demote moderator", actor: domain.ChatRoleAdmin, target: domain.ChatRoleModerator, step: -1, wantRole: domain.ChatRoleMember},
+		{name: "demote member", actor: domain.ChatRoleAdmin, target: domain.ChatRoleMember, step: -1, wantErr: util.ErrNoUpdatedData},
+		{name: "moderator promotes", actor: domain.ChatRoleModerator, target: domain.ChatRoleMember, step: 1, wantErr: util.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _, chatID, userIDs := newChatFixture(tt.actor, tt.target)
+			participant, err := service.changeChatParticipantRole(context.Background(), userIDs[0].String(), chatID.String(), userIDs[1].String(), tt.step)
+			if err != tt.wantErr {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && participant.Role != tt.wantRole {
+				t.Errorf("role = %q, want %q", participant.Role, tt.wantRole)
+			}
+		})
+	}
+}
+
+func TestDirectChatKey(t *testing.T) {
+	a := uuid.MustParse("00000000-0000-0000-0000-00000000000a")
+	b := uuid.MustParse("00000000-0000-0000-0000-00000000000b")
+	c := uuid.MustParse("f0000000-0000-0000-0000-000000000000")
+
+	tests := []struct {
+		first, second uuid.UUID
+		want          string
+	}{
+		{a, b, "00000000-0000-0000-0000-00000000000a:00000000-0000-0000-0000-00000000000b"},
+		{b, a, "00000000-0000-0000-0000-00000000000a:00000000-0000-0000-0000-00000000000b"},
+		{c, a, "00000000-0000-0000-0000-00000000000a:f0000000-0000-0000-0000-000000000000"},
+		{b, c, "00000000-0000-0000-0000-00000000000b:f0000000-0000-0000-0000-000000000000"},
+	}
+
+	for _, tt := range tests {
+		if got := directChatKey(tt.first, tt.second); got != tt.want {
+			t.Errorf("directChatKey(%v, %v) = %q, want %q", tt.first, tt.second, got, tt.want)
+		}
+	}
+	if directChatKey(a, b) == directChatKey(a, c) {
+		t.Errorf("different pairs share the key %q", directChatKey(a, b))
+	}
+}
+
+func TestGetOrCreateDirectChat(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeChatRepository{}
+	publisher := &fakePublisher{}
+	service := NewChatService(repo, &fakeUserRepository{}, nil, publisher)
+	alice, bob := uuid.NewString(), uuid.NewString()
+
+	if _, err := service.GetOrCreateDirectChat(ctx, alice, alice); err != util.ErrInvalidDirectChat {
+		t.Errorf("chat with oneself: error = %v, want %v", err, util.ErrInvalidDirectChat)
+	}
+
+	created, err := service.GetOrCreateDirectChat(ctx, alice, bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.IsGroup || len(created.Participants) != 2 {
+		t.Errorf("created chat is a group: %v, with %d participants", created.IsGroup, len(created.Participants))
+	}
+
+	// either side asking again gets the same chat, and nobody hears about a new one
+	again, err := service.GetOrCreateDirectChat(ctx, bob, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != created.ID {
+		t.Errorf("got chat %v, want the existing %v", again.ID, created.ID)
+	}
+	if len(publisher.events) != 1 || publisher.events[0].Type != domain.EventChatCreated {
+		t.Errorf("published %v, want a single %s", publisher.events, domain.EventChatCreated)
+	}
+}
+
+func TestLeaveDirectChat(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeChatRepository{}
+	service := NewChatService(repo, &fakeUserRepository{}, nil, &fakePublisher{})
+	alice, bob := uuid.NewString(), uuid.NewString()
+
+	created, err := service.GetOrCreateDirectChat(ctx, alice, bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.participants = created.Participants
+	for i := range repo.participants {
+		repo.participants[i].ChatID = created.ID
+	}
+
+	if err := service.LeaveChat(ctx, created.ID.String(), alice); err != util.ErrForbidden {
+		t.Errorf("leaving a direct chat: error = %v, want %v", err, util.ErrForbidden)
+	}
+	if err := service.RemoveChatParticipant(ctx, alice, created.ID.String(), alice); err != util.ErrForbidden {
+		t.Errorf("removing oneself from a direct chat: error = %v, want %v", err, util.ErrForbidden)
+	}
+
+	// the pair keeps talking in the same chat, with both of them in it
+	again, err := service.GetOrCreateDirectChat(ctx, alice, bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != created.ID {
+		t.Errorf("got chat %v, want the existing %v", again.ID, created.ID)
+	}
+	if _, err := repo.GetChatParticipantByChatIDUserID(ctx, again.ID.String(), alice); err != nil {
+		t.Errorf("alice is no longer in the direct chat: %v", err)
+	}
+}
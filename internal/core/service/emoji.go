@@ -0,0 +1,141 @@
+package service
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// maxReactionLength is the longest reaction accepted, in characters like the column storing it
+	maxReactionLength = 32
+	// maxReactionElements is how many emoji a ZWJ sequence may join, as many as the families and couples of Unicode
+	maxReactionElements = 4
+
+	zeroWidthJoiner       = '\u200D'
+	emojiPresentation     = '\uFE0F'
+	combiningEnclosingKey = '\u20E3'
+	regionalIndicatorA    = '\U0001F1E6'
+	regionalIndicatorZ    = '\U0001F1FF'
+	blackFlag             = '\U0001F3F4'
+	cancelTag             = '\U000E007F'
+)
+
+// extendedPictographic holds the Extended_Pictographic characters of Unicode emoji-data.txt,
+// the characters an emoji sequence is built around
+var extendedPictographic = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x00A9, 0x00A9, 1}, {0x00AE, 0x00AE, 1}, {0x203C, 0x203C, 1}, {0x2049, 0x2049, 1},
+		{0x2122, 0x2122, 1}, {0x2139, 0x2139, 1}, {0x2194, 0x2199, 1}, {0x21A9, 0x21AA, 1},
+		{0x231A, 0x231B, 1}, {0x2328, 0x2328, 1}, {0x2388, 0x2388, 1}, {0x23CF, 0x23CF, 1},
+		{0x23E9, 0x23F3, 1}, {0x23F8, 0x23FA, 1}, {0x24C2, 0x24C2, 1}, {0x25AA, 0x25AB, 1},
+		{0x25B6, 0x25B6, 1}, {0x25C0, 0x25C0, 1}, {0x25FB, 0x25FE, 1}, {0x2600, 0x2605, 1},
+		{0x2607, 0x2612, 1}, {0x2614, 0x2685, 1}, {0x2690, 0x2705, 1}, {0x2708, 0x2712, 1},
+		{0x2714, 0x2714, 1}, {0x2716, 0x2716, 1}, {0x271D, 0x271D, 1}, {0x2721, 0x2721, 1},
+		{0x2728, 0x2728, 1}, {0x2733, 0x2734, 1}, {0x2744, 0x2744, 1}, {0x2747, 0x2747, 1},
+		{0x274C, 0x274C, 1}, {0x274E, 0x274E, 1}, {0x2753, 0x2755, 1}, {0x2757, 0x2757, 1},
+		{0x2763, 0x2767, 1}, {0x2795, 0x2797, 1}, {0x27A1, 0x27A1, 1}, {0x27B0, 0x27B0, 1},
+		{0x27BF, 0x27BF, 1}, {0x2934, 0x2935, 1}, {0x2B05, 0x2B07, 1}, {0x2B1B, 0x2B1C, 1},
+		{0x2B50, 0x2B50, 1}, {0x2B55, 0x2B55, 1}, {0x3030, 0x3030, 1}, {0x303D, 0x303D, 1},
+		{0x3297, 0x3297, 1}, {0x3299, 0x3299, 1},
+	},
+	R32: []unicode.Range32{
+		{0x1F000, 0x1F0FF, 1}, {0x1F10D, 0x1F10F, 1}, {0x1F12F, 0x1F12F, 1}, {0x1F16C, 0x1F171, 1},
+		{0x1F17E, 0x1F17F, 1}, {0x1F18E, 0x1F18E, 1}, {0x1F191, 0x1F19A, 1}, {0x1F1AD, 0x1F1E5, 1},
+		{0x1F201, 0x1F20F, 1}, {0x1F21A, 0x1F21A, 1}, {0x1F22F, 0x1F22F, 1}, {0x1F232, 0x1F23A, 1},
+		{0x1F23C, 0x1F23F, 1}, {0x1F249, 0x1F3FA, 1}, {0x1F400, 0x1F53D, 1}, {0x1F546, 0x1F64F, 1},
+		{0x1F680, 0x1F6FF, 1}, {0x1F774, 0x1F77F, 1}, {0x1F7D5, 0x1F7FF, 1}, {0x1F80C, 0x1F80F, 1},
+		{0x1F848, 0x1F84F, 1}, {0x1F85A, 0x1F85F, 1}, {0x1F888, 0x1F88F, 1}, {0x1F8AE, 0x1F8FF, 1},
+		{0x1F90C, 0x1F93A, 1}, {0x1F93C, 0x1F945, 1}, {0x1F947, 0x1FAFF, 1}, {0x1FC00, 0x1FFFD, 1},
+	},
+	LatinOffset: 2,
+}
+
+// validReaction accepts a single emoji as a user would pick it: one pictograph with an optional presentation selector
+// and skin tone, a few of them joined with zero width joiners, a flag or a keycap. Anything longer than one emoji,
+// such as two emoji side by side or text, is rejected.
+func validReaction(emoji string) bool {
+	if !utf8.ValidString(emoji) || utf8.RuneCountInString(emoji) > maxReactionLength {
+		return false
+	}
+
+	runes := []rune(emoji)
+	switch {
+	case len(runes) == 0:
+		return false
+	case isRegionalIndicator(runes[0]):
+		return len(runes) == 2 && isRegionalIndicator(runes[1])
+	case isKeycapBase(runes[0]):
+		return keycapSequence(runes)
+	case runes[0] == blackFlag && len(runes) > 1 && isTag(runes[1]):
+		return tagSequence(runes)
+	}
+	return zwjSequence(runes)
+}
+
+// keycapSequence matches a digit, # or * turned into a key
+func keycapSequence(runes []rune) bool {
+	rest := runes[1:]
+	if len(rest) > 0 && rest[0] == emojiPresentation {
+		rest = rest[1:]
+	}
+	return len(rest) == 1 && rest[0] == combiningEnclosingKey
+}
+
+// tagSequence matches the flags of regions, such as the one of Scotland
+func tagSequence(runes []rune) bool {
+	tags := runes[1 : len(runes)-1]
+	if runes[len(runes)-1] != cancelTag || len(tags) == 0 {
+		return false
+	}
+	for _, r := range tags {
+		if !isTag(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// zwjSequence matches up to maxReactionElements pictographs, each with an optional presentation selector and
+// skin tone, joined by zero width joiners, such as a thumbs up with a skin tone or a couple with a heart
+func zwjSequence(runes []rune) bool {
+	elements := 0
+	for {
+		if len(runes) == 0 || !unicode.Is(extendedPictographic, runes[0]) {
+			return false
+		}
+		runes = runes[1:]
+		if len(runes) > 0 && runes[0] == emojiPresentation {
+			runes = runes[1:]
+		}
+		if len(runes) > 0 && isSkinTone(runes[0]) {
+			runes = runes[1:]
+		}
+
+		if elements++; elements > maxReactionElements {
+			return false
+		}
+		if len(runes) == 0 {
+			return true
+		}
+		if runes[0] != zeroWidthJoiner {
+			return false
+		}
+		runes = runes[1:]
+	}
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= regionalIndicatorA && r <= regionalIndicatorZ
+}
+
+func isKeycapBase(r rune) bool {
+	return r >= '0' && r <= '9' || r == '#' || r == '*'
+}
+
+func isSkinTone(r rune) bool {
+	return r >= '\U0001F3FB' && r <= '\U0001F3FF'
+}
+
+func isTag(r rune) bool {
+	return r >= '\U000E0020' && r <= '\U000E007E'
+}
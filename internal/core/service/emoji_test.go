@@ -0,0 +1,57 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidReaction(t *testing.T) {
+	tests := []struct {
+		name  string
+		emoji string
+		want  bool
+	}{
+		{name: "thumbs up", emoji: "\U0001F44D", want: true},
+		{name: "heart with presentation selector", emoji: "\u2764\uFE0F", want: true},
+		{name: "heart without presentation selector", emoji: "\u2764", want: true},
+		{name: "skin tone", emoji: "\U0001F44D\U0001F3FD", want: true},
+		{name: "family", emoji: "\U0001F468\u200D\U0001F469\u200D\U0001F467\u200D\U0001F466", want: true},
+		{name: "kiss with skin tones", emoji: "\U0001F469\U0001F3FB\u200D\u2764\uFE0F\u200D\U0001F48B\u200D\U0001F468\U0001F3FC", want: true},
+		{name: "rainbow flag", emoji: "\U0001F3F3\uFE0F\u200D\U0001F308", want: true},
+		{name: "country flag", emoji: "\U0001F1FA\U0001F1E6", want: true},
+		{name: "region flag", emoji: "\U0001F3F4\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074\U000E007F", want: true},
+		{name: "black flag", emoji: "\U0001F3F4", want: true},
+		{name: "keycap", emoji: "1\uFE0F\u20E3", want: true},
+		{name: "keycap without presentation selector", emoji: "#\u20E3", want: true},
+		{name: "copyright sign", emoji: "\u00A9\uFE0F", want: true},
+
+		{name: "empty", emoji: ""},
+		{name: "letter", emoji: "a"},
+		{name: "word", emoji: "lol"},
+		{name: "digit", emoji: "1"},
+		{name: "punctuation", emoji: "!"},
+		{name: "arrow that is not an emoji", emoji: "\u2190"},
+		{name: "CJK", emoji: "\u4E2D"},
+		{name: "two emoji", emoji: "\U0001F44D\U0001F44D"},
+		{name: "emoji and text", emoji: "\U0001F44D ok"},
+		{name: "emoji with space", emoji: "\U0001F44D "},
+		{name: "lone skin tone", emoji: "\U0001F3FD"},
+		{name: "lone joiner", emoji: "\u200D"},
+		{name: "trailing joiner", emoji: "\U0001F468\u200D"},
+		{name: "leading joiner", emoji: "\u200D\U0001F468"},
+		{name: "five joined", emoji: strings.Repeat("\U0001F468\u200D", 4) + "\U0001F468"},
+		{name: "lone regional indicator", emoji: "\U0001F1FA"},
+		{name: "three regional indicators", emoji: "\U0001F1FA\U0001F1E6\U0001F1FA"},
+		{name: "region flag without cancel tag", emoji: "\U0001F3F4\U000E0067\U000E0062"},
+		{name: "keycap with two digits", emoji: "12\u20E3"},
+		{name: "text presentation selector", emoji: "\u2764\uFE0E"},
+		{name: "control character", emoji: "\U0001F44D\n"},
+		{name: "invalid UTF-8", emoji: "\xff"},
+	}
+
+	for _, tt := range tests {
+		if got := validReaction(tt.emoji); got != tt.want {
+			t.Errorf("%s: validReaction(%q) = %v, want %v", tt.name, tt.emoji, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/HellEaglee/Golang-Chat/internal/core/port"
+)
+
+// publishEvent announces an already committed change; a failed publish is logged since the change itself cannot be undone
+func publishEvent(ctx context.Context, publisher port.EventPublisher, event *domain.Event) {
+	if err := publisher.Publish(ctx, event); err != nil {
+		slog.Error("Error publishing event", "type", event.Type, "chat_id", event.ChatID, "error", err)
+	}
+}
+
+// publishSignal announces a short-lived state such as a typing indicator; a lost signal is only logged
+func publishSignal(ctx context.Context, publisher port.SignalPublisher, event *domain.Event) {
+	if err := publisher.PublishSignal(ctx, event); err != nil {
+		slog.Error("Error publishing signal", "type", event.Type, "chat_id", event.ChatID, "error", err)
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/HellEaglee/Golang-Chat/internal/core/port"
+)
+
+const (
+	// reaperInterval is how long expired messages can linger; reads hide them in the meantime
+	reaperInterval = 30 * time.Second
+	// reaperBatchSize is how many expired messages are deleted in one transaction
+	reaperBatchSize = 500
+)
+
+type ExpiryService struct {
+	repo      port.MessageRepository
+	blobs     port.BlobStore
+	publisher port.EventPublisher
+}
+
+func NewExpiryService(repo port.MessageRepository, blobs port.BlobStore, publisher port.EventPublisher) *ExpiryService {
+	return &ExpiryService{
+		repo:      repo,
+		blobs:     blobs,
+		publisher: publisher,
+	}
+}
+
+// Run regularly deletes the expired messages, their read receipts and the files attached to them.
+// Several instances can run it at once, each batch skips the messages another one is deleting.
+func (s *ExpiryService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(reaperInterval)
+	defer ticker.Stop()
+
+	for {
+		for s.reap(ctx) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// reap reports whether a full batch was deleted, in which case more messages may have expired
+func (s *ExpiryService) reap(ctx context.Context) bool {
+	messages, unusedKeys, err := s.repo.DeleteExpiredMessages(ctx, reaperBatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("Error deleting expired messages", "error", err)
+		}
+		return false
+	}
+
+	// files shared with forwarded copies stay until the last copy goes
+	for _, key := range unusedKeys {
+		s.deleteBlob(ctx, key)
+	}
+
+	for i := range messages {
+		message := &messages[i]
+		// messages deleted by their author already went out as deleted
+		if !message.DeletedAt.Valid {
+			event := domain.NewEvent(domain.EventMessageDeleted, message.ChatID, message.UserID)
+			event.Message = message
+			publishEvent(ctx, s.publisher, event)
+		}
+	}
+	return len(messages) == reaperBatchSize
+}
+
+func (s *ExpiryService) deleteBlob(ctx context.Context, key string) {
+	if err := s.blobs.Delete(ctx, key); err != nil {
+		slog.Error("Error deleting blob of an expired message", "key", key, "error", err)
+	}
+}
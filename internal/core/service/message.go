@@ -1,39 +1,304 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
 	"github.com/HellEaglee/Golang-Chat/internal/core/port"
+	"github.com/HellEaglee/Golang-Chat/internal/core/util"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultMessagePageSize = 50
+	maxMessagePageSize     = 100
+	defaultSearchPageSize  = 20
 )
 
 type MessageService struct {
-	repo port.MessageRepository
+	repo      port.MessageRepository
+	chatRepo  port.ChatRepository
+	presence  port.PresenceService
+	previews  port.LinkPreviewService
+	publisher port.EventPublisher
+	// editWindow is how long after sending a message can be edited; zero means forever
+	editWindow time.Duration
+	// maxPins is how many messages a chat can have pinned at once; zero means no limit
+	maxPins int
 }
 
-func NewMessageService(repo port.MessageRepository) *MessageService {
-	return &MessageService{repo: repo}
+func NewMessageService(repo port.MessageRepository, chatRepo port.ChatRepository, presence port.PresenceService,
+	previews port.LinkPreviewService, publisher port.EventPublisher, editWindow time.Duration, maxPins int,
+) *MessageService {
+	return &MessageService{
+		repo:       repo,
+		chatRepo:   chatRepo,
+		presence:   presence,
+		previews:   previews,
+		publisher:  publisher,
+		editWindow: editWindow,
+		maxPins:    maxPins,
+	}
 }
 
 // ----------------------------------------------------MESSAGES----------------------------------------------------
 func (s *MessageService) CreateMessage(ctx context.Context, message *domain.Message) (*domain.Message, error) {
-	return s.repo.CreateMessage(ctx, message)
+	if err := formatMessage(message); err != nil {
+		return nil, err
+	}
+	if message.ReplyToMessageID != nil {
+		parent, err := s.repo.GetMessageByID(ctx, message.ReplyToMessageID.String())
+		if err != nil && err != util.ErrDataNotFound {
+			return nil, err
+		}
+		if parent == nil || parent.ChatID != message.ChatID {
+			return nil, util.ErrInvalidReply
+		}
+	}
+
+	mentions, err := s.resolveMentions(ctx, message)
+	if err != nil {
+		return nil, err
+	}
+	message.Mentions = mentions
+
+	return s.create(ctx, message)
+}
+
+// create saves a new message with its link previews and announces it
+func (s *MessageService) create(ctx context.Context, message *domain.Message) (*domain.Message, error) {
+	s.previews.ExtractLinkPreviews(message)
+
+	createdMessage, err := s.repo.CreateMessage(ctx, message)
+	if err != nil {
+		return nil, err
+	}
+	s.announce(ctx, createdMessage)
+
+	return createdMessage, nil
+}
+
+// announce queues the link previews of a saved message and publishes it
+func (s *MessageService) announce(ctx context.Context, message *domain.Message) {
+	s.previews.ScheduleLinkPreviews(message)
+
+	event := domain.NewEvent(domain.EventMessageCreated, message.ChatID, message.UserID)
+	event.Message = message
+	publishEvent(ctx, s.publisher, event)
 }
 
 func (s *MessageService) GetMessage(ctx context.Context, id string) (*domain.Message, error) {
 	return s.repo.GetMessageByID(ctx, id)
 }
 
-func (s *MessageService) GetMessagesByChatID(ctx context.Context, chatID string) ([]domain.Message, error) {
-	return s.repo.GetMessagesByChatID(ctx, chatID)
+func (s *MessageService) GetMessagesByChatID(ctx context.Context, chatID string, page domain.MessagePage) ([]domain.Message, bool, error) {
+	if page.Limit <= 0 {
+		page.Limit = defaultMessagePageSize
+	}
+	if page.Limit > maxMessagePageSize {
+		page.Limit = maxMessagePageSize
+	}
+
+	// fetch one extra row to learn whether the window can be extended further
+	limit := page.Limit
+	page.Limit++
+	messages, err := s.repo.GetMessagesByChatID(ctx, chatID, page)
+	if err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(messages) > limit
+	if hasMore {
+		if page.After != nil {
+			messages = messages[:limit]
+		} else {
+			messages = messages[1:]
+		}
+	}
+	return messages, hasMore, nil
+}
+
+// GetMessagesAfterSeq returns the messages written to the chat after afterSeq, in sequence order
+func (s *MessageService) GetMessagesAfterSeq(ctx context.Context, chatID string, afterSeq int64, limit int) ([]domain.Message, bool, error) {
+	if limit <= 0 {
+		limit = maxMessagePageSize
+	}
+	if limit > maxMessagePageSize {
+		limit = maxMessagePageSize
+	}
+
+	messages, err := s.repo.GetMessagesAfterSeq(ctx, chatID, afterSeq, limit+1)
+	if err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(messages) > limit
+	if hasMore {
+		messages = messages[:limit]
+	}
+	return messages, hasMore, nil
 }
 
 func (s *MessageService) UpdateMessage(ctx context.Context, message *domain.Message) (*domain.Message, error) {
-	return s.repo.UpdateMessage(ctx, message)
+	if message.Kind == domain.MessageKindSystem {
+		return nil, util.ErrForbidden
+	}
+	if s.editWindow > 0 && time.Since(message.CreatedAt) > s.editWindow {
+		return nil, util.ErrEditWindowExpired
+	}
+	if err := formatMessage(message); err != nil {
+		return nil, err
+	}
+	s.previews.ExtractLinkPreviews(message)
+
+	updatedMessage, err := s.repo.UpdateMessage(ctx, message)
+	if err != nil {
+		return nil, err
+	}
+	s.previews.ScheduleLinkPreviews(updatedMessage)
+
+	event := domain.NewEvent(domain.EventMessageUpdated, updatedMessage.ChatID, updatedMessage.UserID)
+	event.Message = updatedMessage
+	publishEvent(ctx, s.publisher, event)
+
+	return updatedMessage, nil
+}
+
+// RenderMessage returns the text of the message as HTML, with everything the user wrote escaped, or as plain text
+func (s *MessageService) RenderMessage(message *domain.Message, format string) (string, error) {
+	switch format {
+	case domain.MessageFormatHTML:
+		return renderHTML(message.Text, message.Entities), nil
+	case domain.MessageFormatPlain:
+		return message.Text, nil
+	}
+	return "", util.ErrInvalidFormatting
 }
 
 func (s *MessageService) DeleteMessage(ctx context.Context, id string) error {
-	return s.repo.DeleteMessage(ctx, id)
+	// load the message first so the event can tell which chat it belonged to
+	message, err := s.repo.GetMessageByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteMessage(ctx, id); err != nil {
+		return err
+	}
+
+	event := domain.NewEvent(domain.EventMessageDeleted, message.ChatID, message.UserID)
+	event.Message = message
+	publishEvent(ctx, s.publisher, event)
+
+	return nil
+}
+
+func (s *MessageService) GetMessageEdits(ctx context.Context, id string) ([]domain.MessageEdit, error) {
+	return s.repo.GetMessageEditsByMessageID(ctx, id)
+}
+
+// ----------------------------------------------------FORWARDING----------------------------------------------------
+// ForwardMessages copies the messages, in the order they were sent, into every target chat on behalf of the user,
+// who must take part in all of them. Nothing is sent unless every message can be forwarded to every chat: the copies
+// are saved in one transaction and only announced once it commits. The target chats are written in the order of
+// their ids, so that concurrent forwards lock them in the same order.
+func (s *MessageService) ForwardMessages(ctx context.Context, userID, chatID string, messageIDs, targetChatIDs []uuid.UUID) ([]domain.Message, error) {
+	messages, err := s.repo.GetMessagesByIDs(ctx, chatID, messageIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) != len(messageIDs) {
+		return nil, util.ErrInvalidForward
+	}
+	for _, message := range messages {
+		if message.Kind == domain.MessageKindSystem {
+			return nil, util.ErrInvalidForward
+		}
+	}
+
+	for _, targetChatID := range targetChatIDs {
+		if _, err := s.chatRepo.GetChatParticipantByChatIDUserID(ctx, targetChatID.String(), userID); err != nil {
+			if err == util.ErrDataNotFound {
+				return nil, util.ErrForbidden
+			}
+			return nil, err
+		}
+	}
+
+	targetChatIDs = slices.Clone(targetChatIDs)
+	slices.SortFunc(targetChatIDs, func(a, b uuid.UUID) int {
+		return bytes.Compare(a[:], b[:])
+	})
+
+	forwarder := uuid.MustParse(userID)
+	copies := make([]*domain.Message, 0, len(messages)*len(targetChatIDs))
+	for _, targetChatID := range targetChatIDs {
+		for i := range messages {
+			message := newForwardedMessage(&messages[i], targetChatID, forwarder)
+			s.previews.ExtractLinkPreviews(message)
+			copies = append(copies, message)
+		}
+	}
+	if err := s.repo.CreateMessages(ctx, copies); err != nil {
+		return nil, err
+	}
+
+	forwarded := make([]domain.Message, len(copies))
+	for i, message := range copies {
+		s.announce(ctx, message)
+		forwarded[i] = *message
+	}
+	return forwarded, nil
+}
+
+// newForwardedMessage copies the message into the chat. The origin of a forward is kept, and the attachments
+// are copied as new rows pointing at the same files. Mentions are not carried over as they named the other chat.
+func newForwardedMessage(original *domain.Message, chatID, userID uuid.UUID) *domain.Message {
+	message := &domain.Message{
+		ID:                     uuid.New(),
+		ChatID:                 chatID,
+		UserID:                 userID,
+		Text:                   original.Text,
+		Entities:               original.Entities,
+		ForwardedFromMessageID: &original.ID,
+		ForwardedFromChatID:    &original.ChatID,
+		ForwardedFromUserID:    &original.UserID,
+	}
+	if original.ForwardedFromMessageID != nil {
+		message.ForwardedFromMessageID = original.ForwardedFromMessageID
+		message.ForwardedFromChatID = original.ForwardedFromChatID
+		message.ForwardedFromUserID = original.ForwardedFromUserID
+	}
+
+	for _, attachment := range original.Attachments {
+		copied := domain.Attachment{
+			ID:              uuid.New(),
+			ChatID:          chatID,
+			UserID:          userID,
+			FileName:        attachment.FileName,
+			ContentType:     attachment.ContentType,
+			Size:            attachment.Size,
+			StorageKey:      attachment.StorageKey,
+			Width:           attachment.Width,
+			Height:          attachment.Height,
+			ThumbnailStatus: attachment.ThumbnailStatus,
+		}
+		// an image still being processed is queued again, the worker writes the thumbnails to the same keys
+		if copied.ThumbnailStatus == domain.ThumbnailStatusProcessing {
+			copied.ThumbnailStatus = domain.ThumbnailStatusPending
+		}
+		for _, thumbnail := range attachment.Thumbnails {
+			thumbnail.AttachmentID = copied.ID
+			copied.Thumbnails = append(copied.Thumbnails, thumbnail)
+		}
+		message.Attachments = append(message.Attachments, copied)
+	}
+	return message
 }
 
 // ----------------------------------------------------MESSAGE_READS----------------------------------------------------
@@ -48,3 +313,340 @@ func (s *MessageService) GetMessageReadsByMessageID(ctx context.Context, id stri
 func (s *MessageService) DeleteMessageRead(ctx context.Context, messageID, userID string) error {
 	return s.repo.DeleteMessageRead(ctx, messageID, userID)
 }
+
+// ----------------------------------------------------RECEIPTS----------------------------------------------------
+// MarkDelivered records that the user received the message and everything sent before it in the chat
+func (s *MessageService) MarkDelivered(ctx context.Context, message *domain.Message, userID string) error {
+	marked, err := s.repo.MarkMessagesDelivered(ctx, message, userID)
+	if err != nil {
+		return err
+	}
+	if marked > 0 {
+		s.publishReceipt(ctx, domain.EventMessageDelivered, message, userID)
+	}
+	return nil
+}
+
+// MarkRead records that the user read the message and everything sent before it in the chat
+func (s *MessageService) MarkRead(ctx context.Context, message *domain.Message, userID string) error {
+	marked, err := s.repo.MarkMessagesRead(ctx, message, userID)
+	if err != nil {
+		return err
+	}
+	if marked > 0 {
+		s.publishReceipt(ctx, domain.EventMessageRead, message, userID)
+	}
+	return nil
+}
+
+func (s *MessageService) publishReceipt(ctx context.Context, eventType domain.EventType, message *domain.Message, userID string) {
+	event := domain.NewEvent(eventType, message.ChatID, uuid.MustParse(userID))
+	event.Message = message
+	publishEvent(ctx, s.publisher, event)
+}
+
+// GetMessageStatus returns the state of the message for each recipient; the overall status only moves forward
+// once every recipient got there, so a group message is read when all its participants have read it
+func (s *MessageService) GetMessageStatus(ctx context.Context, message *domain.Message) (*domain.MessageStatus, error) {
+	receipts, err := s.repo.GetMessageReceipts(ctx, message.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	status := &domain.MessageStatus{MessageID: message.ID, Receipts: receipts}
+	for i := range receipts {
+		switch {
+		case receipts[i].ReadAt != nil:
+			receipts[i].Status = domain.MessageStatusRead
+			status.ReadCount++
+			status.DeliveredCount++
+		case receipts[i].DeliveredAt != nil:
+			receipts[i].Status = domain.MessageStatusDelivered
+			status.DeliveredCount++
+		default:
+			receipts[i].Status = domain.MessageStatusSent
+		}
+	}
+
+	switch {
+	case len(receipts) == 0:
+		status.Status = domain.MessageStatusSent
+	case status.ReadCount == len(receipts):
+		status.Status = domain.MessageStatusRead
+	case status.DeliveredCount == len(receipts):
+		status.Status = domain.MessageStatusDelivered
+	default:
+		status.Status = domain.MessageStatusSent
+	}
+	return status, nil
+}
+
+// ----------------------------------------------------REACTIONS----------------------------------------------------
+func (s *MessageService) AddReaction(ctx context.Context, message *domain.Message, userID, emoji string) ([]domain.ReactionCount, error) {
+	if !validReaction(emoji) {
+		return nil, util.ErrInvalidReaction
+	}
+
+	reaction, err := s.repo.CreateMessageReaction(ctx, &domain.MessageReaction{
+		MessageID: message.ID,
+		UserID:    uuid.MustParse(userID),
+		Emoji:     emoji,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	event := domain.NewEvent(domain.EventReactionAdded, message.ChatID, reaction.UserID)
+	event.Reaction = reaction
+	publishEvent(ctx, s.publisher, event)
+
+	return s.repo.GetReactionCounts(ctx, []uuid.UUID{message.ID}, userID)
+}
+
+func (s *MessageService) RemoveReaction(ctx context.Context, message *domain.Message, userID, emoji string) ([]domain.ReactionCount, error) {
+	reaction := &domain.MessageReaction{
+		MessageID: message.ID,
+		UserID:    uuid.MustParse(userID),
+		Emoji:     emoji,
+	}
+	if err := s.repo.DeleteMessageReaction(ctx, reaction); err != nil {
+		return nil, err
+	}
+
+	event := domain.NewEvent(domain.EventReactionRemoved, message.ChatID, reaction.UserID)
+	event.Reaction = reaction
+	publishEvent(ctx, s.publisher, event)
+
+	return s.repo.GetReactionCounts(ctx, []uuid.UUID{message.ID}, userID)
+}
+
+func (s *MessageService) LoadReactions(ctx context.Context, messages []domain.Message, userID string) error {
+	messageIDs := make([]uuid.UUID, len(messages))
+	for i, message := range messages {
+		messageIDs[i] = message.ID
+	}
+
+	counts, err := s.repo.GetReactionCounts(ctx, messageIDs, userID)
+	if err != nil {
+		return err
+	}
+
+	byMessage := make(map[uuid.UUID][]domain.ReactionCount, len(messages))
+	for _, count := range counts {
+		byMessage[count.MessageID] = append(byMessage[count.MessageID], count)
+	}
+	for i := range messages {
+		messages[i].Reactions = byMessage[messages[i].ID]
+	}
+	return nil
+}
+
+// ----------------------------------------------------THREADS----------------------------------------------------
+// GetThreadReplies returns a page of the direct replies to root, paginated like the chat history
+func (s *MessageService) GetThreadReplies(ctx context.Context, root *domain.Message, page domain.MessagePage) ([]domain.Message, bool, error) {
+	page.ThreadID = &root.ID
+	return s.GetMessagesByChatID(ctx, root.ChatID.String(), page)
+}
+
+func (s *MessageService) LoadThreadSummaries(ctx context.Context, messages []domain.Message) error {
+	messageIDs := make([]uuid.UUID, len(messages))
+	for i, message := range messages {
+		messageIDs[i] = message.ID
+	}
+
+	summaries, err := s.repo.GetThreadSummaries(ctx, messageIDs)
+	if err != nil {
+		return err
+	}
+
+	byMessage := make(map[uuid.UUID]domain.ThreadSummary, len(summaries))
+	for _, summary := range summaries {
+		byMessage[summary.MessageID] = summary
+	}
+	for i := range messages {
+		messages[i].Thread = byMessage[messages[i].ID]
+		messages[i].Thread.MessageID = messages[i].ID
+	}
+	return nil
+}
+
+// ----------------------------------------------------PINS----------------------------------------------------
+func (s *MessageService) PinMessage(ctx context.Context, message *domain.Message, userID string) (*domain.PinnedMessage, error) {
+	pinnedBy := uuid.MustParse(userID)
+	pin, err := s.repo.CreatePinnedMessage(ctx, &domain.PinnedMessage{
+		MessageID: message.ID,
+		ChatID:    message.ChatID,
+		PinnedBy:  &pinnedBy,
+	}, s.maxPins)
+	if err != nil {
+		return nil, err
+	}
+	pin.Message = *message
+
+	event := domain.NewEvent(domain.EventMessagePinned, message.ChatID, pinnedBy)
+	event.Pin = pin
+	publishEvent(ctx, s.publisher, event)
+
+	return pin, nil
+}
+
+func (s *MessageService) UnpinMessage(ctx context.Context, message *domain.Message, userID string) error {
+	if err := s.repo.DeletePinnedMessage(ctx, message.ID.String()); err != nil {
+		return err
+	}
+
+	event := domain.NewEvent(domain.EventMessageUnpinned, message.ChatID, uuid.MustParse(userID))
+	event.Message = message
+	publishEvent(ctx, s.publisher, event)
+
+	return nil
+}
+
+func (s *MessageService) GetPinnedMessages(ctx context.Context, chatID string) ([]domain.PinnedMessage, error) {
+	return s.repo.GetPinnedMessagesByChatID(ctx, chatID)
+}
+
+// ----------------------------------------------------MENTIONS----------------------------------------------------
+// mentionPattern matches @handle tokens that are not part of a word or an email address
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@.])@([\w.-]{1,50})`)
+
+// parseMentions returns the lowercased handles mentioned in the text and whether @all or @here were used
+func parseMentions(text string) (handles map[string]struct{}, all, here bool) {
+	handles = make(map[string]struct{})
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		// a handle cannot end a sentence
+		handle := strings.ToLower(strings.TrimRight(match[1], ".-"))
+		switch handle {
+		case "":
+		case "all":
+			all = true
+		case "here":
+			here = true
+		default:
+			handles[handle] = struct{}{}
+		}
+	}
+	return handles, all, here
+}
+
+// handleMentions reports whether a participant with the lowercased name is mentioned by handle
+func handleMentions(handles map[string]struct{}, names map[string]int, name string) bool {
+	_, ok := handles[name]
+	return ok && names[name] == 1
+}
+
+// resolveMentions turns the mentions in the text of the message into mention rows for the other participants of the chat.
+// Handles are matched against user names, which are not unique: a handle shared by several participants of the chat
+// mentions none of them, like a handle that matches no participant, and is left as plain text.
+// The @all and @here handles only mean something in group chats, where @here reaches the participants who are online.
+func (s *MessageService) resolveMentions(ctx context.Context, message *domain.Message) ([]domain.MessageMention, error) {
+	handles, all, here := parseMentions(message.Text)
+	if len(handles) == 0 && !all && !here {
+		return nil, nil
+	}
+
+	if all || here {
+		chat, err := s.chatRepo.GetChatByID(ctx, message.ChatID.String())
+		if err != nil {
+			return nil, err
+		}
+		if !chat.IsGroup {
+			all, here = false, false
+		}
+	}
+
+	participants, err := s.chatRepo.GetChatParticipantsByChatID(ctx, message.ChatID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	// names counts the participants going by each name, to tell the handles that single one out
+	names := make(map[string]int, len(participants))
+	for _, participant := range participants {
+		names[strings.ToLower(participant.User.Name)]++
+	}
+
+	var statuses map[uuid.UUID]string
+	if here && !all {
+		userIDs := make([]uuid.UUID, len(participants))
+		for i, participant := range participants {
+			userIDs[i] = participant.UserID
+		}
+		if statuses, err = s.presence.GetStatuses(ctx, userIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	var mentions []domain.MessageMention
+	for _, participant := range participants {
+		if participant.UserID == message.UserID {
+			continue
+		}
+
+		var kind string
+		if name := strings.ToLower(participant.User.Name); handleMentions(handles, names, name) {
+			kind = domain.MentionKindUser
+		} else if all {
+			kind = domain.MentionKindAll
+		} else if here && statuses[participant.UserID] == domain.PresenceOnline {
+			kind = domain.MentionKindHere
+		} else {
+			continue
+		}
+
+		mentions = append(mentions, domain.MessageMention{
+			MessageID: message.ID,
+			UserID:    participant.UserID,
+			ChatID:    message.ChatID,
+			Kind:      kind,
+		})
+	}
+	return mentions, nil
+}
+
+// GetMentions returns a page of the mentions of the user, newest first
+func (s *MessageService) GetMentions(ctx context.Context, userID string, page domain.MentionPage) ([]domain.MessageMention, bool, error) {
+	if page.Limit <= 0 {
+		page.Limit = defaultMessagePageSize
+	}
+	if page.Limit > maxMessagePageSize {
+		page.Limit = maxMessagePageSize
+	}
+
+	limit := page.Limit
+	page.Limit++
+	mentions, err := s.repo.GetMentionsByUserID(ctx, userID, page)
+	if err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(mentions) > limit
+	if hasMore {
+		mentions = mentions[:limit]
+	}
+	return mentions, hasMore, nil
+}
+
+// ----------------------------------------------------SEARCH----------------------------------------------------
+// SearchMessages returns a page of the messages matching the search among the chats of the user, best matches first
+func (s *MessageService) SearchMessages(ctx context.Context, userID string, search domain.MessageSearch) ([]domain.MessageSearchResult, bool, error) {
+	if search.Limit <= 0 {
+		search.Limit = defaultSearchPageSize
+	}
+	if search.Limit > maxMessagePageSize {
+		search.Limit = maxMessagePageSize
+	}
+
+	limit := search.Limit
+	search.Limit++
+	results, err := s.repo.SearchMessages(ctx, userID, search)
+	if err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(results) > limit
+	if hasMore {
+		results = results[:limit]
+	}
+	return results, hasMore, nil
+}
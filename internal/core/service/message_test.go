@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/HellEaglee/Golang-Chat/internal/core/port"
+	"github.com/google/uuid"
+)
+
+// fakeMessageRepository serves a fixed set of messages; methods the tests do not need panic
+type fakeMessageRepository struct {
+	port.MessageRepository
+	messages  []domain.Message
+	createErr error
+	created   []*domain.Message
+}
+
+func (r *fakeMessageRepository) GetMessagesByIDs(ctx context.Context, chatID string, ids []uuid.UUID) ([]domain.Message, error) {
+	return r.messages, nil
+}
+
+func (r *fakeMessageRepository) UpdateMessage(ctx context.Context, message *domain.Message) (*domain.Message, error) {
+	updated := *message
+	return &updated, nil
+}
+
+func (r *fakeMessageRepository) CreateMessages(ctx context.Context, messages []*domain.Message) error {
+	if r.createErr != nil {
+		return r.createErr
+	}
+	r.created = append(r.created, messages...)
+	return nil
+}
+
+type fakeLinkPreviewService struct {
+	port.LinkPreviewService
+	scheduled int
+}
+
+func (s *fakeLinkPreviewService) ExtractLinkPreviews(message *domain.Message) {}
+
+func (s *fakeLinkPreviewService) ScheduleLinkPreviews(message *domain.Message) {
+	s.scheduled++
+}
+
+func TestForwardMessagesIsAllOrNothing(t *testing.T) {
+	userID := uuid.New()
+	sourceChatID := uuid.New()
+	targetChatIDs := []uuid.UUID{uuid.New(), uuid.New()}
+
+	chatRepo := &fakeChatRepository{}
+	for _, chatID := range append([]uuid.UUID{sourceChatID}, targetChatIDs...) {
+		chatRepo.participants = append(chatRepo.participants, domain.ChatParticipant{ChatID: chatID, UserID: userID, Role: domain.ChatRoleMember})
+	}
+	originals := []domain.Message{
+		{ID: uuid.New(), ChatID: sourceChatID, UserID: uuid.New(), Kind: domain.MessageKindUser, Text: "first"},
+		{ID: uuid.New(), ChatID: sourceChatID, UserID: uuid.New(), Kind: domain.MessageKindUser, Text: "second"},
+	}
+	messageIDs := []uuid.UUID{originals[0].ID, originals[1].ID}
+
+	tests := []struct {
+		name      string
+		createErr error
+		wantSent  int
+	}{
+		{name: "every copy is sent", wantSent: 4},
+		{name: "a failed copy sends nothing", createErr: errors.New("connection reset")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeMessageRepository{messages: originals, createErr: tt.createErr}
+			previews := &fakeLinkPreviewService{}
+			publisher := &fakePublisher{}
+			service := NewMessageService(repo, chatRepo, nil, previews, publisher, 0, 0)
+
+			forwarded, err := service.ForwardMessages(context.Background(), userID.String(), sourceChatID.String(), messageIDs, targetChatIDs)
+			if err != tt.createErr {
+				t.Fatalf("error = %v, want %v", err, tt.createErr)
+			}
+			if len(forwarded) != tt.wantSent || len(publisher.events) != tt.wantSent || previews.scheduled != tt.wantSent {
+				t.Fatalf("sent %d, published %d and scheduled %d, want %d of each",
+					len(forwarded), len(publisher.events), previews.scheduled, tt.wantSent)
+			}
+
+			for i, message := range forwarded {
+				original := originals[i%len(originals)]
+				if message.Text != original.Text || *message.ForwardedFromMessageID != original.ID {
+					t.Errorf("copy %d is %q from %v, want %q from %v", i, message.Text, *message.ForwardedFromMessageID, original.Text, original.ID)
+				}
+				if message.UserID != userID {
+					t.Errorf("copy %d is sent by %v, want the forwarder", i, message.UserID)
+				}
+			}
+		})
+	}
+}
+
+func (r *fakeChatRepository) GetChatByID(ctx context.Context, id string) (*domain.Chat, error) {
+	for _, chat := range r.directChats {
+		if chat.ID.String() == id {
+			return chat, nil
+		}
+	}
+	return &domain.Chat{ID: uuid.MustParse(id), IsGroup: true}, nil
+}
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		text        string
+		wantHandles []string
+		wantAll     bool
+		wantHere    bool
+	}{
+		{text: "no mentions here"},
+		{text: "@alice hi", wantHandles: []string{"alice"}},
+		{text: "hi @Alice and @BOB", wantHandles: []string{"alice", "bob"}},
+		{text: "thanks @alice.", wantHandles: []string{"alice"}},
+		{text: "ask @john.doe-", wantHandles: []string{"john.doe"}},
+		{text: "(@alice)", wantHandles: []string{"alice"}},
+		{text: "write to alice@example.com", wantHandles: nil},
+		{text: "a@alice", wantHandles: nil},
+		{text: "@@alice", wantHandles: nil},
+		{text: "@alice @alice", wantHandles: []string{"alice"}},
+		{text: "@all look", wantAll: true},
+		{text: "@HERE look", wantHere: true},
+		{text: "@all and @here with @bob", wantHandles: []string{"bob"}, wantAll: true, wantHere: true},
+		{text: "just @ and @.", wantHandles: nil},
+	}
+
+	for _, tt := range tests {
+		handles, all, here := parseMentions(tt.text)
+		var got []string
+		for handle := range handles {
+			got = append(got, handle)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tt.wantHandles) || all != tt.wantAll || here != tt.wantHere {
+			t.Errorf("parseMentions(%q) = %v, %v, %v, want %v, %v, %v", tt.text, got, all, here, tt.wantHandles, tt.wantAll, tt.wantHere)
+		}
+	}
+}
+
+func TestResolveMentions(t *testing.T) {
+	chatID := uuid.New()
+	author := domain.ChatParticipant{ChatID: chatID, UserID: uuid.New(), User: domain.User{Name: "Alice"}}
+	bob := domain.ChatParticipant{ChatID: chatID, UserID: uuid.New(), User: domain.User{Name: "Bob"}}
+	firstSam := domain.ChatParticipant{ChatID: chatID, UserID: uuid.New(), User: domain.User{Name: "Sam"}}
+	secondSam := domain.ChatParticipant{ChatID: chatID, UserID: uuid.New(), User: domain.User{Name: "sam"}}
+	participants := []domain.ChatParticipant{author, bob, firstSam, secondSam}
+
+	tests := []struct {
+		text string
+		want map[uuid.UUID]string
+	}{
+		{text: "hello @bob", want: map[uuid.UUID]string{bob.UserID: domain.MentionKindUser}},
+		{text: "hello @nobody"},
+		{text: "note to self @alice"},
+		// two participants go by sam, so the handle cannot tell which one is meant
+		{text: "hello @sam"},
+		{text: "@sam @bob", want: map[uuid.UUID]string{bob.UserID: domain.MentionKindUser}},
+		{text: "@all and @sam", want: map[uuid.UUID]string{
+			bob.UserID:       domain.MentionKindAll,
+			firstSam.UserID:  domain.MentionKindAll,
+			secondSam.UserID: domain.MentionKindAll,
+		}},
+	}
+
+	for _, tt := range tests {
+		service := NewMessageService(nil, &fakeChatRepository{participants: participants}, nil, nil, nil, 0, 0)
+		message := &domain.Message{ID: uuid.New(), ChatID: chatID, UserID: author.UserID, Text: tt.text}
+
+		mentions, err := service.resolveMentions(context.Background(), message)
+		if err != nil {
+			t.Fatalf("resolveMentions(%q): %v", tt.text, err)
+		}
+		got := make(map[uuid.UUID]string)
+		for _, mention := range mentions {
+			got[mention.UserID] = mention.Kind
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("resolveMentions(%q) mentions %v, want %v", tt.text, got, tt.want)
+			continue
+		}
+		for userID, kind := range tt.want {
+			if got[userID] != kind {
+				t.Errorf("resolveMentions(%q) mentions %v, want %v", tt.text, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestUpdateMessageRelinksPreviews(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		format      string
+		wantLinks   []string
+		wantFetches int
+	}{
+		{name: "link added", text: "now with https://example.com/new", wantLinks: []string{"https://example.com/new"}, wantFetches: 1},
+		{name: "Markdown link added", text: "[new](https://example.com/new)", format: domain.MessageFormatMarkdown, wantLinks: []string{"https://example.com/new"}, wantFetches: 1},
+		{name: "link removed", text: "no link anymore"},
+	}
+
+	for _, tt := range tests {
+		previews := NewLinkPreviewService(nil, nil, nil)
+		service := NewMessageService(&fakeMessageRepository{}, nil, nil, previews, &fakePublisher{}, 0, 0)
+		message := &domain.Message{
+			ID:           uuid.New(),
+			Kind:         domain.MessageKindUser,
+			Text:         tt.text,
+			Format:       tt.format,
+			LinkPreviews: []domain.LinkPreview{{URL: "https://example.com/old"}},
+		}
+
+		updated, err := service.UpdateMessage(context.Background(), message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var links []string
+		for _, preview := range updated.LinkPreviews {
+			links = append(links, preview.URL)
+		}
+		if !slices.Equal(links, tt.wantLinks) {
+			t.Errorf("%s: previews of the edited message = %v, want %v", tt.name, links, tt.wantLinks)
+		}
+		if len(previews.jobs) != tt.wantFetches {
+			t.Errorf("%s: %d fetches queued, want %d", tt.name, len(previews.jobs), tt.wantFetches)
+		}
+	}
+}
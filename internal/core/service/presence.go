@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/HellEaglee/Golang-Chat/internal/core/port"
+	"github.com/google/uuid"
+)
+
+const (
+	// presenceHeartbeatInterval is how often an instance tells the others the connections it serves are still open
+	presenceHeartbeatInterval = 30 * time.Second
+	// presenceStaleAfter is how long a connection counts without a heartbeat, long enough to ride out a missed one
+	presenceStaleAfter = 3 * presenceHeartbeatInterval
+)
+
+// PresenceService derives the status of users from their realtime connections: online while any connection is active,
+// away once every connection reports the user idle and offline without connections.
+// Connections are stored, so every instance sees those of the others; a connection stops counting once the instance
+// serving it misses its heartbeats, and whichever instance sweeps it tells the contacts of the user.
+type PresenceService struct {
+	repo      port.PresenceRepository
+	userRepo  port.UserRepository
+	chatRepo  port.ChatRepository
+	publisher port.EventPublisher
+
+	mu sync.Mutex
+	// connections are the connections served by this instance, by id
+	connections map[uuid.UUID]domain.PresenceConnection
+}
+
+func NewPresenceService(repo port.PresenceRepository, userRepo port.UserRepository, chatRepo port.ChatRepository, publisher port.EventPublisher) *PresenceService {
+	return &PresenceService{
+		repo:        repo,
+		userRepo:    userRepo,
+		chatRepo:    chatRepo,
+		publisher:   publisher,
+		connections: make(map[uuid.UUID]domain.PresenceConnection),
+	}
+}
+
+// Connect keeps the connection even when saving it fails, the next heartbeat saves it again
+func (s *PresenceService) Connect(ctx context.Context, userID string) (string, error) {
+	connection := domain.PresenceConnection{ID: uuid.New(), UserID: uuid.MustParse(userID), SeenAt: time.Now()}
+
+	s.mu.Lock()
+	s.connections[connection.ID] = connection
+	s.mu.Unlock()
+
+	return connection.ID.String(), s.save(ctx, connection)
+}
+
+func (s *PresenceService) SetAway(ctx context.Context, userID, connectionID string, away bool) error {
+	id, err := uuid.Parse(connectionID)
+	if err != nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	connection, ok := s.connections[id]
+	ok = ok && connection.UserID.String() == userID
+	if ok {
+		connection.Away = away
+		connection.SeenAt = time.Now()
+		s.connections[id] = connection
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	return s.save(ctx, connection)
+}
+
+func (s *PresenceService) Disconnect(ctx context.Context, userID, connectionID string) error {
+	if id, err := uuid.Parse(connectionID); err == nil {
+		s.mu.Lock()
+		delete(s.connections, id)
+		s.mu.Unlock()
+	}
+
+	before, after, err := s.repo.DeletePresenceConnection(ctx, userID, connectionID, presenceStaleAfter)
+	if err != nil {
+		return err
+	}
+	return s.changed(ctx, uuid.MustParse(userID), presenceStatus(before), presenceStatus(after))
+}
+
+func (s *PresenceService) GetStatuses(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]string, error) {
+	connections, err := s.repo.GetPresenceConnectionsByUserIDs(ctx, userIDs, presenceStaleAfter)
+	if err != nil {
+		return nil, err
+	}
+
+	byUser := groupPresenceConnections(connections)
+	statuses := make(map[uuid.UUID]string, len(userIDs))
+	for _, userID := range userIDs {
+		statuses[userID] = presenceStatus(byUser[userID])
+	}
+	return statuses, nil
+}
+
+func (s *PresenceService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(presenceHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		s.heartbeat(ctx)
+		s.sweep(ctx)
+	}
+}
+
+// heartbeat keeps the connections of this instance from going stale
+func (s *PresenceService) heartbeat(ctx context.Context) {
+	s.mu.Lock()
+	ids := slices.Collect(maps.Keys(s.connections))
+	s.mu.Unlock()
+	if len(ids) == 0 {
+		return
+	}
+
+	touched, err := s.repo.TouchPresenceConnections(ctx, ids, time.Now())
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("Error refreshing presence connections", "error", err)
+		}
+		return
+	}
+
+	alive := make(map[uuid.UUID]bool, len(touched))
+	for _, connection := range touched {
+		alive[connection.ID] = true
+	}
+	// connections that failed to save or were swept while this instance could not reach the others are saved again,
+	// which announces their users back
+	for _, id := range ids {
+		if alive[id] {
+			continue
+		}
+
+		s.mu.Lock()
+		connection, ok := s.connections[id]
+		s.mu.Unlock()
+		if !ok {
+			continue
+		}
+
+		connection.SeenAt = time.Now()
+		if err := s.save(ctx, connection); err != nil && ctx.Err() == nil {
+			slog.Error("Error saving presence connection", "user_id", connection.UserID, "error", err)
+		}
+	}
+}
+
+// sweep drops the connections of instances that went away and announces the users that went with them
+func (s *PresenceService) sweep(ctx context.Context) {
+	swept, err := s.repo.DeleteStalePresenceConnections(ctx, presenceStaleAfter)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("Error sweeping presence connections", "error", err)
+		}
+		return
+	}
+	if len(swept) == 0 {
+		return
+	}
+
+	sweptByUser := groupPresenceConnections(swept)
+	userIDs := slices.Collect(maps.Keys(sweptByUser))
+	live, err := s.repo.GetPresenceConnectionsByUserIDs(ctx, userIDs, presenceStaleAfter)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("Error sweeping presence connections", "error", err)
+		}
+		return
+	}
+
+	liveByUser := groupPresenceConnections(live)
+	for _, userID := range userIDs {
+		// nobody was told the swept connections were gone, so they still count for the status users last heard of
+		before := presenceStatus(append(liveByUser[userID], sweptByUser[userID]...))
+		if err := s.changed(ctx, userID, before, presenceStatus(liveByUser[userID])); err != nil && ctx.Err() == nil {
+			slog.Error("Error updating presence", "user_id", userID, "error", err)
+		}
+	}
+}
+
+func (s *PresenceService) save(ctx context.Context, connection domain.PresenceConnection) error {
+	before, after, err := s.repo.SavePresenceConnection(ctx, &connection, presenceStaleAfter)
+	if err != nil {
+		return err
+	}
+	return s.changed(ctx, connection.UserID, presenceStatus(before), presenceStatus(after))
+}
+
+// changed records the time the user was last seen and tells everyone sharing a chat with them, if the status moved
+func (s *PresenceService) changed(ctx context.Context, userID uuid.UUID, before, after string) error {
+	if before == after {
+		return nil
+	}
+
+	lastSeenAt := time.Now()
+	if err := s.userRepo.UpdateUserLastSeen(ctx, userID.String(), lastSeenAt); err != nil {
+		return err
+	}
+
+	contactIDs, err := s.chatRepo.GetContactIDsByUserID(ctx, userID.String())
+	if err != nil {
+		return err
+	}
+
+	event := domain.NewEvent(domain.EventPresenceChanged, uuid.Nil, userID)
+	event.Presence = &domain.Presence{UserID: userID, Status: after, LastSeenAt: &lastSeenAt}
+	// the other devices of the user follow along too
+	event.Recipients = append(contactIDs, userID)
+	publishEvent(ctx, s.publisher, event)
+
+	return nil
+}
+
+// presenceStatus is the status of a user with the given live connections
+func presenceStatus(connections []domain.PresenceConnection) string {
+	if len(connections) == 0 {
+		return domain.PresenceOffline
+	}
+	for _, connection := range connections {
+		if !connection.Away {
+			return domain.PresenceOnline
+		}
+	}
+	return domain.PresenceAway
+}
+
+func groupPresenceConnections(connections []domain.PresenceConnection) map[uuid.UUID][]domain.PresenceConnection {
+	byUser := make(map[uuid.UUID][]domain.PresenceConnection)
+	for _, connection := range connections {
+		byUser[connection.UserID] = append(byUser[connection.UserID], connection)
+	}
+	return byUser
+}
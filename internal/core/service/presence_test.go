@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/HellEaglee/Golang-Chat/internal/core/port"
+	"github.com/google/uuid"
+)
+
+// fakePresenceRepository stands for the table every instance shares
+type fakePresenceRepository struct {
+	mu          sync.Mutex
+	connections map[uuid.UUID]domain.PresenceConnection
+}
+
+func newFakePresenceRepository() *fakePresenceRepository {
+	return &fakePresenceRepository{connections: make(map[uuid.UUID]domain.PresenceConnection)}
+}
+
+func (r *fakePresenceRepository) live(userID uuid.UUID, staleAfter time.Duration) []domain.PresenceConnection {
+	var connections []domain.PresenceConnection
+	for _, connection := range r.connections {
+		if connection.UserID == userID && connection.SeenAt.After(time.Now().Add(-staleAfter)) {
+			connections = append(connections, connection)
+		}
+	}
+	return connections
+}
+
+func (r *fakePresenceRepository) SavePresenceConnection(ctx context.Context, connection *domain.PresenceConnection, staleAfter time.Duration) ([]domain.PresenceConnection, []domain.PresenceConnection, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	before := r.live(connection.UserID, staleAfter)
+	r.connections[connection.ID] = *connection
+	return before, r.live(connection.UserID, staleAfter), nil
+}
+
+func (r *fakePresenceRepository) DeletePresenceConnection(ctx context.Context, userID, id string, staleAfter time.Duration) ([]domain.PresenceConnection, []domain.PresenceConnection, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	before := r.live(uuid.MustParse(userID), staleAfter)
+	delete(r.connections, uuid.MustParse(id))
+	return before, r.live(uuid.MustParse(userID), staleAfter), nil
+}
+
+func (r *fakePresenceRepository) GetPresenceConnectionsByUserIDs(ctx context.Context, userIDs []uuid.UUID, staleAfter time.Duration) ([]domain.PresenceConnection, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var connections []domain.PresenceConnection
+	for _, userID := range userIDs {
+		connections = append(connections, r.live(userID, staleAfter)...)
+	}
+	return connections, nil
+}
+
+func (r *fakePresenceRepository) TouchPresenceConnections(ctx context.Context, ids []uuid.UUID, seenAt time.Time) ([]domain.PresenceConnection, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var touched []domain.PresenceConnection
+	for _, id := range ids {
+		if connection, ok := r.connections[id]; ok {
+			connection.SeenAt = seenAt
+			r.connections[id] = connection
+			touched = append(touched, connection)
+		}
+	}
+	return touched, nil
+}
+
+func (r *fakePresenceRepository) DeleteStalePresenceConnections(ctx context.Context, staleAfter time.Duration) ([]domain.PresenceConnection, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var deleted []domain.PresenceConnection
+	for id, connection := range r.connections {
+		if !connection.SeenAt.After(time.Now().Add(-staleAfter)) {
+			deleted = append(deleted, connection)
+			delete(r.connections, id)
+		}
+	}
+	return deleted, nil
+}
+
+// age makes every connection look like its last heartbeat was that long ago
+func (r *fakePresenceRepository) age(by time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, connection := range r.connections {
+		connection.SeenAt = connection.SeenAt.Add(-by)
+		r.connections[id] = connection
+	}
+}
+
+type fakeUserRepository struct {
+	port.UserRepository
+}
+
+func (r *fakeUserRepository) GetUserByID(ctx context.Context, id string) (*domain.User, error) {
+	return &domain.User{ID: uuid.MustParse(id)}, nil
+}
+
+func (r *fakeUserRepository) UpdateUserLastSeen(ctx context.Context, id string, lastSeenAt time.Time) error {
+	return nil
+}
+
+func (r *fakeChatRepository) GetContactIDsByUserID(ctx context.Context, userID string) ([]uuid.UUID, error) {
+	return nil, nil
+}
+
+// statuses returns the statuses announced by presence.changed events
+func statuses(publisher *fakePublisher) []string {
+	var announced []string
+	for _, event := range publisher.events {
+		announced = append(announced, event.Presence.Status)
+	}
+	return announced
+}
+
+func TestPresenceStatus(t *testing.T) {
+	active := domain.PresenceConnection{}
+	idle := domain.PresenceConnection{Away: true}
+
+	tests := []struct {
+		name        string
+		connections []domain.PresenceConnection
+		want        string
+	}{
+		{name: "no connections", want: domain.PresenceOffline},
+		{name: "one active", connections: []domain.PresenceConnection{active}, want: domain.PresenceOnline},
+		{name: "one idle", connections: []domain.PresenceConnection{idle}, want: domain.PresenceAway},
+		{name: "every device idle", connections: []domain.PresenceConnection{idle, idle}, want: domain.PresenceAway},
+		{name: "any device active", connections: []domain.PresenceConnection{idle, active, idle}, want: domain.PresenceOnline},
+	}
+
+	for _, tt := range tests {
+		if got := presenceStatus(tt.connections); got != tt.want {
+			t.Errorf("%s: status = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPresenceAcrossInstances(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakePresenceRepository()
+	publisher := &fakePublisher{}
+	first := NewPresenceService(repo, &fakeUserRepository{}, &fakeChatRepository{}, publisher)
+	second := NewPresenceService(repo, &fakeUserRepository{}, &fakeChatRepository{}, publisher)
+	userID := uuid.New()
+
+	phone, _ := first.Connect(ctx, userID.String())
+	laptop, _ := second.Connect(ctx, userID.String())
+	// closing one device leaves the user online on the other instance
+	if err := first.Disconnect(ctx, userID.String(), phone); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := first.GetStatuses(ctx, []uuid.UUID{userID})
+	if got[userID] != domain.PresenceOnline {
+		t.Errorf("status seen by the first instance = %q, want online", got[userID])
+	}
+
+	second.SetAway(ctx, userID.String(), laptop, true)
+	second.Disconnect(ctx, userID.String(), laptop)
+
+	want := []string{domain.PresenceOnline, domain.PresenceAway, domain.PresenceOffline}
+	if announced := statuses(publisher); !slices.Equal(announced, want) {
+		t.Fatalf("announced %v, want %v", announced, want)
+	}
+}
+
+func TestPresenceSweep(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakePresenceRepository()
+	publisher := &fakePublisher{}
+	crashed := NewPresenceService(repo, &fakeUserRepository{}, &fakeChatRepository{}, publisher)
+	survivor := NewPresenceService(repo, &fakeUserRepository{}, &fakeChatRepository{}, publisher)
+	userID := uuid.New()
+
+	crashed.Connect(ctx, userID.String())
+	survivor.sweep(ctx)
+	if announced := statuses(publisher); !slices.Equal(announced, []string{domain.PresenceOnline}) {
+		t.Fatalf("announced %v before the connection went stale", announced)
+	}
+
+	repo.age(presenceStaleAfter)
+	got, _ := survivor.GetStatuses(ctx, []uuid.UUID{userID})
+	if got[userID] != domain.PresenceOffline {
+		t.Errorf("status of a stale connection = %q, want offline", got[userID])
+	}
+	survivor.sweep(ctx)
+	survivor.sweep(ctx)
+	if announced := statuses(publisher); !slices.Equal(announced, []string{domain.PresenceOnline, domain.PresenceOffline}) {
+		t.Fatalf("announced %v, want a single offline after the sweep", announced)
+	}
+
+	// an instance that only lost touch with the others for a while brings its connections back
+	crashed.heartbeat(ctx)
+	if announced := statuses(publisher); announced[len(announced)-1] != domain.PresenceOnline {
+		t.Fatalf("announced %v, want the user back online after the heartbeat", announced)
+	}
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/HellEaglee/Golang-Chat/internal/core/port"
+)
+
+const (
+	// maxLinkPreviews is how many links of a message get a preview
+	maxLinkPreviews = 3
+	// maxPreviewURLLength keeps absurd links out of the cache
+	maxPreviewURLLength = 2048
+	// previewTTL is how long a fetched preview is reused before being fetched again
+	previewTTL = 24 * time.Hour
+	// previewRetryAfter is how long a failed fetch is remembered, so broken links are not fetched for every message
+	previewRetryAfter = time.Hour
+	// previewWorkers is how many pages are fetched at the same time
+	previewWorkers = 4
+	// previewQueueSize bounds the messages waiting for their previews; further ones go without
+	previewQueueSize = 256
+)
+
+// linkPattern finds http(s) links in message text
+var linkPattern = regexp.MustCompile(`https?://[^\s<>"]+`)
+
+type LinkPreviewService struct {
+	repo      port.LinkPreviewRepository
+	fetcher   port.LinkPreviewFetcher
+	publisher port.EventPublisher
+	jobs      chan *domain.Message
+}
+
+func NewLinkPreviewService(repo port.LinkPreviewRepository, fetcher port.LinkPreviewFetcher, publisher port.EventPublisher) *LinkPreviewService {
+	return &LinkPreviewService{
+		repo:      repo,
+		fetcher:   fetcher,
+		publisher: publisher,
+		jobs:      make(chan *domain.Message, previewQueueSize),
+	}
+}
+
+func (s *LinkPreviewService) ExtractLinkPreviews(message *domain.Message) {
+	message.LinkPreviews = nil
+	for _, link := range extractLinks(message.Text, message.Entities) {
+		message.LinkPreviews = append(message.LinkPreviews, domain.LinkPreview{URL: link})
+	}
+}
+
+func (s *LinkPreviewService) ScheduleLinkPreviews(message *domain.Message) {
+	if !slices.ContainsFunc(message.LinkPreviews, stalePreview) {
+		return
+	}
+
+	// the worker updates its own copy, the caller keeps using the message
+	job := *message
+	job.LinkPreviews = slices.Clone(message.LinkPreviews)
+
+	select {
+	case s.jobs <- &job:
+	default:
+		slog.Warn("Link preview queue is full, skipping message", "message_id", message.ID)
+	}
+}
+
+func (s *LinkPreviewService) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for range previewWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case message := <-s.jobs:
+					s.preview(ctx, message)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// preview fetches the stale previews of the message and lets the chat know once some are ready
+func (s *LinkPreviewService) preview(ctx context.Context, message *domain.Message) {
+	updated := false
+	for i := range message.LinkPreviews {
+		preview := &message.LinkPreviews[i]
+		if !stalePreview(*preview) {
+			continue
+		}
+
+		now := time.Now()
+		fetched, err := s.fetcher.Fetch(ctx, preview.URL)
+		if err != nil {
+			slog.Debug("Error fetching link preview", "url", preview.URL, "error", err)
+			fetched = &domain.LinkPreview{URL: preview.URL, Status: domain.LinkPreviewFailed}
+		} else {
+			fetched.Status = domain.LinkPreviewReady
+			updated = true
+		}
+		fetched.FetchedAt = &now
+		fetched.CreatedAt = preview.CreatedAt
+
+		if err := s.repo.UpdateLinkPreview(ctx, fetched); err != nil {
+			slog.Error("Error saving link preview", "url", preview.URL, "error", err)
+			continue
+		}
+		*preview = *fetched
+	}
+
+	if updated {
+		event := domain.NewEvent(domain.EventMessagePreviewed, message.ChatID, message.UserID)
+		event.Message = message
+		publishEvent(ctx, s.publisher, event)
+	}
+}
+
+// stalePreview tells whether the preview is missing or old enough to be fetched again
+func stalePreview(preview domain.LinkPreview) bool {
+	if preview.FetchedAt == nil {
+		return true
+	}
+	switch preview.Status {
+	case domain.LinkPreviewReady:
+		return time.Since(*preview.FetchedAt) > previewTTL
+	case domain.LinkPreviewFailed:
+		return time.Since(*preview.FetchedAt) > previewRetryAfter
+	}
+	return true
+}
+
+// extractLinks returns the distinct http(s) links of the message in text order, without trailing punctuation and
+// fragments. Links written out in the text and the targets of link entities both count, as Markdown keeps only the
+// label of a link in the text.
+func extractLinks(text string, entities []domain.MessageEntity) []string {
+	type candidate struct {
+		offset int
+		raw    string
+	}
+	var candidates []candidate
+
+	// offsets are in UTF-16 code units like those of entities
+	offset, last := 0, 0
+	for _, match := range linkPattern.FindAllStringIndex(text, -1) {
+		for _, r := range text[last:match[0]] {
+			offset += utf16Len(r)
+		}
+		last = match[0]
+		raw := strings.TrimRight(text[match[0]:match[1]], ".,;:!?'\")]}")
+		candidates = append(candidates, candidate{offset: offset, raw: raw})
+	}
+	for _, entity := range entities {
+		if entity.Type == domain.EntityLink {
+			candidates = append(candidates, candidate{offset: entity.Offset, raw: entity.URL})
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int { return a.offset - b.offset })
+
+	var links []string
+	seen := make(map[string]struct{})
+	for _, candidate := range candidates {
+		link, err := url.Parse(candidate.raw)
+		if err != nil || link.Host == "" || len(candidate.raw) > maxPreviewURLLength {
+			continue
+		}
+		link.Scheme = strings.ToLower(link.Scheme)
+		if link.Scheme != "http" && link.Scheme != "https" {
+			continue
+		}
+		link.Host = strings.ToLower(link.Host)
+		link.Fragment = ""
+
+		normalized := link.String()
+		if _, ok := seen[normalized]; ok {
+			continue
+		}
+		seen[normalized] = struct{}{}
+
+		links = append(links, normalized)
+		if len(links) == maxLinkPreviews {
+			break
+		}
+	}
+	return links
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+)
+
+func TestExtractLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		entities []domain.MessageEntity
+		want     []string
+	}{
+		{name: "none", text: "no links here"},
+		{name: "written out", text: "see https://example.com/a, then http://Example.org/b#top.", want: []string{"https://example.com/a", "http://example.org/b"}},
+		{name: "link entity", text: "docs", entities: []domain.MessageEntity{link(0, 4, "https://example.com/docs")}, want: []string{"https://example.com/docs"}},
+		{
+			name:     "entities and text in text order",
+			text:     "first https://one.example then docs and https://three.example",
+			entities: []domain.MessageEntity{link(31, 4, "https://two.example")},
+			want:     []string{"https://one.example", "https://two.example", "https://three.example"},
+		},
+		{
+			name:     "offsets count UTF-16 code units",
+			text:     "\U0001F600\U0001F600 a https://two.example",
+			entities: []domain.MessageEntity{link(5, 1, "https://one.example")},
+			want:     []string{"https://one.example", "https://two.example"},
+		},
+		{
+			name:     "entity and text sharing a link",
+			text:     "docs at https://example.com/docs",
+			entities: []domain.MessageEntity{link(0, 4, "https://example.com/docs")},
+			want:     []string{"https://example.com/docs"},
+		},
+		{
+			name:     "mail links get no preview",
+			text:     "mail",
+			entities: []domain.MessageEntity{link(0, 4, "mailto:someone@example.com")},
+		},
+		{
+			name: "the cut keeps the first links",
+			text: "a b c d",
+			entities: []domain.MessageEntity{
+				link(0, 1, "https://a.example"), link(2, 1, "https://b.example"), link(4, 1, "https://c.example"), link(6, 1, "https://d.example"),
+			},
+			want: []string{"https://a.example", "https://b.example", "https://c.example"},
+		},
+		{name: "bold is not a link", text: "bold", entities: []domain.MessageEntity{entity(domain.EntityBold, 0, 4)}},
+	}
+
+	for _, tt := range tests {
+		if got := extractLinks(tt.text, tt.entities); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: extractLinks = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExtractLinkPreviewsOfMarkdown(t *testing.T) {
+	message := &domain.Message{Text: "[docs](https://example.com/docs)", Format: domain.MessageFormatMarkdown}
+	if err := formatMessage(message); err != nil {
+		t.Fatal(err)
+	}
+	(&LinkPreviewService{}).ExtractLinkPreviews(message)
+	if len(message.LinkPreviews) != 1 || message.LinkPreviews[0].URL != "https://example.com/docs" {
+		t.Errorf("previews of %q = %v, want one of https://example.com/docs", message.Text, message.LinkPreviews)
+	}
+}
@@ -0,0 +1,439 @@
+package service
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/HellEaglee/Golang-Chat/internal/core/util"
+)
+
+const (
+	// maxMessageLength is how many characters the text of a message may have once its formatting is parsed
+	maxMessageLength   = 4096
+	maxMessageEntities = 100
+	// escapableMarkdown are the characters a backslash turns into plain text
+	escapableMarkdown = "\\`*_[]()>"
+)
+
+var codeLanguageRegexp = regexp.MustCompile(`^[A-Za-z0-9_+#.-]{1,32}$`)
+
+// formatMessage turns the text of the message into plain text and entities according to its format, and checks the
+// result against the limits
+func formatMessage(message *domain.Message) error {
+	switch message.Format {
+	case "", domain.MessageFormatPlain:
+		message.Entities = nil
+	case domain.MessageFormatMarkdown:
+		message.Text, message.Entities = parseMarkdown(message.Text)
+	default:
+		return util.ErrInvalidFormatting
+	}
+
+	if utf8.RuneCountInString(message.Text) > maxMessageLength {
+		return util.ErrMessageTooLong
+	}
+	return validateEntities(message.Text, message.Entities)
+}
+
+// ----------------------------------------------------PARSING----------------------------------------------------
+// markdownParser reads **bold**, *italic* or _italic_, `code`, fenced code blocks, [links](https://example.com) and
+// lines quoted with >. Markup that is never closed is kept as text, so any input parses.
+type markdownParser struct {
+	text strings.Builder
+	// length is the length of text in UTF-16 code units
+	length   int
+	entities []domain.MessageEntity
+	inLink   bool
+}
+
+// parseMarkdown returns the plain text of the source with its entities ordered by offset, outer ones first
+func parseMarkdown(source string) (string, []domain.MessageEntity) {
+	p := &markdownParser{}
+	lines := strings.Split(source, "\n")
+	for i := 0; i < len(lines); i++ {
+		if i > 0 {
+			p.write("\n")
+		}
+
+		line := lines[i]
+		if language, ok := parseFence(line); ok {
+			if end := closingFence(lines, i+1); end > 0 {
+				p.span(domain.MessageEntity{Type: domain.EntityPre, Language: language}, func() {
+					p.write(strings.Join(lines[i+1:end], "\n"))
+				})
+				i = end
+				continue
+			}
+		}
+		if strings.HasPrefix(line, ">") {
+			end := i + 1
+			for end < len(lines) && strings.HasPrefix(lines[end], ">") {
+				end++
+			}
+			p.quote(lines[i:end])
+			i = end - 1
+			continue
+		}
+		p.inline(line)
+	}
+
+	slices.SortStableFunc(p.entities, compareEntities)
+	return p.text.String(), p.entities
+}
+
+// parseFence reports whether the line opens or closes a code block, along with the language it names
+func parseFence(line string) (string, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimRight(line, " \t\r"), "```")
+	if !ok || (rest != "" && !codeLanguageRegexp.MatchString(rest)) {
+		return "", false
+	}
+	return rest, true
+}
+
+func closingFence(lines []string, from int) int {
+	for i := from; i < len(lines); i++ {
+		if language, ok := parseFence(lines[i]); ok && language == "" {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *markdownParser) quote(lines []string) {
+	p.span(domain.MessageEntity{Type: domain.EntityQuote}, func() {
+		for i, line := range lines {
+			if i > 0 {
+				p.write("\n")
+			}
+			line = strings.TrimPrefix(line, ">")
+			p.inline(strings.TrimPrefix(line, " "))
+		}
+	})
+}
+
+func (p *markdownParser) inline(s string) {
+	for i := 0; i < len(s); {
+		n := p.markup(s, i)
+		if n == 0 {
+			_, n = utf8.DecodeRuneInString(s[i:])
+			p.write(s[i : i+n])
+		}
+		i += n
+	}
+}
+
+// markup writes the markup starting at s[i] and returns how many bytes it took, or zero when there is none
+func (p *markdownParser) markup(s string, i int) int {
+	switch {
+	case s[i] == '\\' && i+1 < len(s) && strings.IndexByte(escapableMarkdown, s[i+1]) >= 0:
+		p.write(s[i+1 : i+2])
+		return 2
+	case s[i] == '`':
+		end := strings.IndexByte(s[i+1:], '`')
+		if end <= 0 {
+			return 0
+		}
+		p.span(domain.MessageEntity{Type: domain.EntityCode}, func() {
+			p.write(s[i+1 : i+1+end])
+		})
+		return end + 2
+	case strings.HasPrefix(s[i:], "**"):
+		if n := p.emphasis(s, i, "**", domain.EntityBold); n > 0 {
+			return n
+		}
+		// an unclosed ** is not an italic marker either
+		p.write("**")
+		return 2
+	case s[i] == '*' || s[i] == '_':
+		// snake_case words are not italic
+		if s[i] == '_' && i > 0 && isWordByte(s[i-1]) {
+			return 0
+		}
+		return p.emphasis(s, i, s[i:i+1], domain.EntityItalic)
+	case s[i] == '[' && !p.inLink:
+		return p.link(s, i)
+	}
+	return 0
+}
+
+func (p *markdownParser) emphasis(s string, i int, marker, entityType string) int {
+	from := i + len(marker)
+	if from >= len(s) || unicode.IsSpace(rune(s[from])) {
+		return 0
+	}
+	end := closingMarker(s, from, marker)
+	if end < 0 {
+		return 0
+	}
+	p.span(domain.MessageEntity{Type: entityType}, func() {
+		p.inline(s[from:end])
+	})
+	return end + len(marker) - i
+}
+
+// closingMarker finds the marker that closes an emphasis opened right before s[from]
+func closingMarker(s string, from int, marker string) int {
+	for j := from + 1; j < len(s); j++ {
+		switch {
+		case s[j] == '\\':
+			j++
+		case s[j] == '`':
+			// markers inside a code span do not count
+			if end := strings.IndexByte(s[j+1:], '`'); end > 0 {
+				j += end + 1
+			}
+		case marker == "*" && strings.HasPrefix(s[j:], "**"):
+			// a bold marker inside the italic
+			j++
+		case strings.HasPrefix(s[j:], marker) && !unicode.IsSpace(rune(s[j-1])):
+			if marker == "**" && strings.HasPrefix(s[j:], "***") {
+				// ***text*** closes the italic inside first
+				continue
+			}
+			if marker == "_" && j+1 < len(s) && isWordByte(s[j+1]) {
+				continue
+			}
+			return j
+		}
+	}
+	return -1
+}
+
+func (p *markdownParser) link(s string, i int) int {
+	depth := 0
+	closing := -1
+	for j := i; j < len(s) && closing < 0; j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '[':
+			depth++
+		case ']':
+			if depth--; depth == 0 {
+				closing = j
+			}
+		}
+	}
+	if closing <= i+1 || closing+1 >= len(s) || s[closing+1] != '(' {
+		return 0
+	}
+	end := strings.IndexByte(s[closing+2:], ')')
+	if end < 0 {
+		return 0
+	}
+	target := s[closing+2 : closing+2+end]
+	if !validLinkURL(target) {
+		return 0
+	}
+
+	p.inLink = true
+	p.span(domain.MessageEntity{Type: domain.EntityLink, URL: target}, func() {
+		p.inline(s[i+1 : closing])
+	})
+	p.inLink = false
+	return closing + 2 + end + 1 - i
+}
+
+// span records the entity over whatever write adds to the text; spans that turn out empty are dropped
+func (p *markdownParser) span(entity domain.MessageEntity, write func()) {
+	entity.Offset = p.length
+	write()
+	entity.Length = p.length - entity.Offset
+	if entity.Length > 0 {
+		p.entities = append(p.entities, entity)
+	}
+}
+
+func (p *markdownParser) write(s string) {
+	p.text.WriteString(s)
+	for _, r := range s {
+		p.length += utf16Len(r)
+	}
+}
+
+func utf16Len(r rune) int {
+	if n := utf16.RuneLen(r); n > 0 {
+		return n
+	}
+	return 1
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= utf8.RuneSelf
+}
+
+func compareEntities(a, b domain.MessageEntity) int {
+	if a.Offset != b.Offset {
+		return a.Offset - b.Offset
+	}
+	return b.Length - a.Length
+}
+
+// validLinkURL only lets through absolute links that cannot run script when clicked
+func validLinkURL(raw string) bool {
+	if raw == "" || len(raw) > 2048 || strings.ContainsAny(raw, " \t\r\n<>\"") {
+		return false
+	}
+	link, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(link.Scheme) {
+	case "http", "https":
+		return link.Host != ""
+	case "mailto":
+		return link.Opaque != ""
+	}
+	return false
+}
+
+// ----------------------------------------------------VALIDATION----------------------------------------------------
+// validateEntities checks that the entities are ordered like parseMarkdown orders them, lie within the text without
+// splitting a character, and nest without overlapping. Nothing can be nested in code, and links not in links.
+func validateEntities(text string, entities []domain.MessageEntity) error {
+	if len(entities) > maxMessageEntities {
+		return util.ErrInvalidFormatting
+	}
+	if !slices.IsSortedFunc(entities, compareEntities) {
+		return util.ErrInvalidFormatting
+	}
+
+	units := utf16.Encode([]rune(text))
+	var open []domain.MessageEntity
+	for _, entity := range entities {
+		if !validEntity(entity) {
+			return util.ErrInvalidFormatting
+		}
+		end := entity.Offset + entity.Length
+		if entity.Offset < 0 || entity.Length <= 0 || end > len(units) {
+			return util.ErrInvalidFormatting
+		}
+		if isLowSurrogate(units[entity.Offset]) || end < len(units) && isLowSurrogate(units[end]) {
+			return util.ErrInvalidFormatting
+		}
+
+		for len(open) > 0 && entityEnd(open[len(open)-1]) <= entity.Offset {
+			open = open[:len(open)-1]
+		}
+		for _, parent := range open {
+			if entityEnd(parent) < end || parent.Type == domain.EntityCode || parent.Type == domain.EntityPre ||
+				parent.Type == domain.EntityLink && entity.Type == domain.EntityLink {
+				return util.ErrInvalidFormatting
+			}
+		}
+		open = append(open, entity)
+	}
+	return nil
+}
+
+func validEntity(entity domain.MessageEntity) bool {
+	switch entity.Type {
+	case domain.EntityBold, domain.EntityItalic, domain.EntityCode, domain.EntityQuote:
+		return entity.URL == "" && entity.Language == ""
+	case domain.EntityPre:
+		return entity.URL == "" && (entity.Language == "" || codeLanguageRegexp.MatchString(entity.Language))
+	case domain.EntityLink:
+		return validLinkURL(entity.URL) && entity.Language == ""
+	}
+	return false
+}
+
+func entityEnd(entity domain.MessageEntity) int {
+	return entity.Offset + entity.Length
+}
+
+func isLowSurrogate(unit uint16) bool {
+	return unit >= 0xDC00 && unit < 0xE000
+}
+
+// ----------------------------------------------------RENDERING----------------------------------------------------
+// renderHTML escapes the text and wraps the entities in tags. Line breaks become <br> outside code blocks.
+func renderHTML(text string, entities []domain.MessageEntity) string {
+	units := utf16.Encode([]rune(text))
+	var b strings.Builder
+	var open []domain.MessageEntity
+	pos, next, inPre := 0, 0, 0
+	for {
+		for len(open) > 0 && entityEnd(open[len(open)-1]) == pos {
+			entity := open[len(open)-1]
+			open = open[:len(open)-1]
+			b.WriteString(closingTag(entity))
+			if entity.Type == domain.EntityPre {
+				inPre--
+			}
+		}
+		for next < len(entities) && entities[next].Offset == pos {
+			entity := entities[next]
+			next++
+			open = append(open, entity)
+			b.WriteString(openingTag(entity))
+			if entity.Type == domain.EntityPre {
+				inPre++
+			}
+		}
+		if pos == len(units) {
+			break
+		}
+
+		stop := len(units)
+		if len(open) > 0 {
+			stop = min(stop, entityEnd(open[len(open)-1]))
+		}
+		if next < len(entities) {
+			stop = min(stop, entities[next].Offset)
+		}
+		chunk := html.EscapeString(string(utf16.Decode(units[pos:stop])))
+		if inPre == 0 {
+			chunk = strings.ReplaceAll(chunk, "\n", "<br>\n")
+		}
+		b.WriteString(chunk)
+		pos = stop
+	}
+	return b.String()
+}
+
+func openingTag(entity domain.MessageEntity) string {
+	switch entity.Type {
+	case domain.EntityBold:
+		return "<strong>"
+	case domain.EntityItalic:
+		return "<em>"
+	case domain.EntityCode:
+		return "<code>"
+	case domain.EntityPre:
+		if entity.Language != "" {
+			return `<pre><code class="language-` + html.EscapeString(entity.Language) + `">`
+		}
+		return "<pre><code>"
+	case domain.EntityLink:
+		return `<a href="` + html.EscapeString(entity.URL) + `" rel="nofollow noopener noreferrer" target="_blank">`
+	case domain.EntityQuote:
+		return "<blockquote>"
+	}
+	return ""
+}
+
+func closingTag(entity domain.MessageEntity) string {
+	switch entity.Type {
+	case domain.EntityBold:
+		return "</strong>"
+	case domain.EntityItalic:
+		return "</em>"
+	case domain.EntityCode:
+		return "</code>"
+	case domain.EntityPre:
+		return "</code></pre>"
+	case domain.EntityLink:
+		return "</a>"
+	case domain.EntityQuote:
+		return "</blockquote>"
+	}
+	return ""
+}
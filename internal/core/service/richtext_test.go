@@ -0,0 +1,250 @@
+package service
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/HellEaglee/Golang-Chat/internal/core/util"
+)
+
+func entity(entityType string, offset, length int) domain.MessageEntity {
+	return domain.MessageEntity{Type: entityType, Offset: offset, Length: length}
+}
+
+func link(offset, length int, url string) domain.MessageEntity {
+	return domain.MessageEntity{Type: domain.EntityLink, Offset: offset, Length: length, URL: url}
+}
+
+func TestParseMarkdown(t *testing.T) {
+	tests := []struct {
+		source       string
+		wantText     string
+		wantEntities []domain.MessageEntity
+	}{
+		{source: "plain text", wantText: "plain text"},
+		{source: "**bold** text", wantText: "bold text", wantEntities: []domain.MessageEntity{entity(domain.EntityBold, 0, 4)}},
+		{
+			source:       "*one* and _two_",
+			wantText:     "one and two",
+			wantEntities: []domain.MessageEntity{entity(domain.EntityItalic, 0, 3), entity(domain.EntityItalic, 8, 3)},
+		},
+		{source: "snake_case_name", wantText: "snake_case_name"},
+		{source: "`a*b*`", wantText: "a*b*", wantEntities: []domain.MessageEntity{entity(domain.EntityCode, 0, 4)}},
+		{source: "**unclosed *bold", wantText: "**unclosed *bold"},
+		{source: "* not italic *", wantText: "* not italic *"},
+		{source: `\*escaped\*`, wantText: "*escaped*"},
+		{
+			source:       "**bold *and italic***",
+			wantText:     "bold and italic",
+			wantEntities: []domain.MessageEntity{entity(domain.EntityBold, 0, 15), entity(domain.EntityItalic, 5, 10)},
+		},
+		{
+			source:       "[site](https://example.com)",
+			wantText:     "site",
+			wantEntities: []domain.MessageEntity{link(0, 4, "https://example.com")},
+		},
+		{
+			source:       "**see [site](https://example.com)**",
+			wantText:     "see site",
+			wantEntities: []domain.MessageEntity{entity(domain.EntityBold, 0, 8), link(4, 4, "https://example.com")},
+		},
+		{source: "[x](javascript:alert(1))", wantText: "[x](javascript:alert(1))"},
+		{source: "[x](JavaScript:alert(1))", wantText: "[x](JavaScript:alert(1))"},
+		{source: "[x](data:text/html,hi)", wantText: "[x](data:text/html,hi)"},
+		{source: "[x](//example.com)", wantText: "[x](//example.com)"},
+		{
+			source:       "[mail](mailto:someone@example.com)",
+			wantText:     "mail",
+			wantEntities: []domain.MessageEntity{link(0, 4, "mailto:someone@example.com")},
+		},
+		{
+			source:       "> quoted\n> more\nafter",
+			wantText:     "quoted\nmore\nafter",
+			wantEntities: []domain.MessageEntity{entity(domain.EntityQuote, 0, 11)},
+		},
+		{
+			source:       "```go\nfmt.Println(\"**\")\n```",
+			wantText:     "fmt.Println(\"**\")",
+			wantEntities: []domain.MessageEntity{{Type: domain.EntityPre, Offset: 0, Length: 17, Language: "go"}},
+		},
+		{source: "```\nnever closed", wantText: "```\nnever closed"},
+		// an emoji takes two UTF-16 code units
+		{
+			source:       "\U0001F600 **hi**",
+			wantText:     "\U0001F600 hi",
+			wantEntities: []domain.MessageEntity{entity(domain.EntityBold, 3, 2)},
+		},
+		{
+			source:       "**\U0001F600**",
+			wantText:     "\U0001F600",
+			wantEntities: []domain.MessageEntity{entity(domain.EntityBold, 0, 2)},
+		},
+		{source: "****", wantText: "****"},
+	}
+
+	for _, tt := range tests {
+		text, entities := parseMarkdown(tt.source)
+		if text != tt.wantText || !slices.Equal(entities, tt.wantEntities) {
+			t.Errorf("parseMarkdown(%q) = %q, %v, want %q, %v", tt.source, text, entities, tt.wantText, tt.wantEntities)
+		}
+		if err := validateEntities(text, entities); err != nil {
+			t.Errorf("parseMarkdown(%q) gives entities that do not validate: %v", tt.source, err)
+		}
+	}
+}
+
+func TestValidateEntities(t *testing.T) {
+	// the emoji takes the code units 0 and 1
+	const text = "\U0001F600 hi there"
+
+	tests := []struct {
+		name     string
+		entities []domain.MessageEntity
+		wantErr  bool
+	}{
+		{name: "none"},
+		{name: "after the emoji", entities: []domain.MessageEntity{entity(domain.EntityBold, 3, 2)}},
+		{name: "the whole emoji", entities: []domain.MessageEntity{entity(domain.EntityBold, 0, 2)}},
+		{name: "the whole text", entities: []domain.MessageEntity{entity(domain.EntityBold, 0, 11)}},
+		{
+			name:     "nested",
+			entities: []domain.MessageEntity{entity(domain.EntityBold, 0, 11), entity(domain.EntityItalic, 3, 2), link(6, 5, "https://example.com")},
+		},
+		{
+			name:     "side by side",
+			entities: []domain.MessageEntity{entity(domain.EntityBold, 3, 2), entity(domain.EntityItalic, 5, 1)},
+		},
+		{name: "starting inside the emoji", entities: []domain.MessageEntity{entity(domain.EntityBold, 1, 2)}, wantErr: true},
+		{name: "ending inside the emoji", entities: []domain.MessageEntity{entity(domain.EntityBold, 0, 1)}, wantErr: true},
+		{name: "past the end", entities: []domain.MessageEntity{entity(domain.EntityBold, 3, 9)}, wantErr: true},
+		{name: "negative offset", entities: []domain.MessageEntity{entity(domain.EntityBold, -1, 2)}, wantErr: true},
+		{name: "empty", entities: []domain.MessageEntity{entity(domain.EntityBold, 3, 0)}, wantErr: true},
+		{name: "unknown type", entities: []domain.MessageEntity{entity("underline", 3, 2)}, wantErr: true},
+		{name: "bold with a URL", entities: []domain.MessageEntity{{Type: domain.EntityBold, Offset: 3, Length: 2, URL: "https://example.com"}}, wantErr: true},
+		{name: "javascript link", entities: []domain.MessageEntity{link(3, 2, "javascript:alert(1)")}, wantErr: true},
+		{name: "relative link", entities: []domain.MessageEntity{link(3, 2, "/admin")}, wantErr: true},
+		{name: "link with a quote", entities: []domain.MessageEntity{link(3, 2, `https://example.com/"onclick=`)}, wantErr: true},
+		{name: "code block with a bad language", entities: []domain.MessageEntity{{Type: domain.EntityPre, Offset: 3, Length: 2, Language: "a b"}}, wantErr: true},
+		{
+			name:     "out of order",
+			entities: []domain.MessageEntity{entity(domain.EntityItalic, 5, 1), entity(domain.EntityBold, 3, 2)},
+			wantErr:  true,
+		},
+		{
+			name:     "overlapping",
+			entities: []domain.MessageEntity{entity(domain.EntityBold, 3, 3), entity(domain.EntityItalic, 5, 3)},
+			wantErr:  true,
+		},
+		{
+			name:     "inside code",
+			entities: []domain.MessageEntity{entity(domain.EntityCode, 3, 8), entity(domain.EntityBold, 3, 2)},
+			wantErr:  true,
+		},
+		{
+			name:     "link inside a link",
+			entities: []domain.MessageEntity{link(3, 8, "https://example.com"), link(6, 5, "https://example.org")},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		err := validateEntities(text, tt.entities)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, want an error: %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	tooMany := make([]domain.MessageEntity, maxMessageEntities+1)
+	for i := range tooMany {
+		tooMany[i] = entity(domain.EntityBold, 0, 2)
+	}
+	if err := validateEntities(text, tooMany); err != util.ErrInvalidFormatting {
+		t.Errorf("%d entities: error = %v, want %v", len(tooMany), err, util.ErrInvalidFormatting)
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		entities []domain.MessageEntity
+		want     string
+	}{
+		{name: "escaped", text: `<script>alert("hi")</script> & co`, want: "&lt;script&gt;alert(&#34;hi&#34;)&lt;/script&gt; &amp; co"},
+		{name: "line breaks", text: "one\ntwo", want: "one<br>\ntwo"},
+		{name: "after an emoji", text: "\U0001F600 hi", entities: []domain.MessageEntity{entity(domain.EntityBold, 3, 2)}, want: "\U0001F600 <strong>hi</strong>"},
+		{name: "around an emoji", text: "a\U0001F600b", entities: []domain.MessageEntity{entity(domain.EntityItalic, 1, 2)}, want: "a<em>\U0001F600</em>b"},
+		{
+			name:     "nested",
+			text:     "see site now",
+			entities: []domain.MessageEntity{entity(domain.EntityBold, 0, 8), link(4, 4, "https://example.com/?a=1&b=\"2\"")},
+			want:     `<strong>see <a href="https://example.com/?a=1&amp;b=&#34;2&#34;" rel="nofollow noopener noreferrer" target="_blank">site</a></strong> now`,
+		},
+		{
+			name:     "code block keeps line breaks",
+			text:     "a\n<b>",
+			entities: []domain.MessageEntity{{Type: domain.EntityPre, Offset: 0, Length: 5, Language: "html"}},
+			want:     "<pre><code class=\"language-html\">a\n&lt;b&gt;</code></pre>",
+		},
+		{
+			name:     "quote",
+			text:     "one\ntwo\nthree",
+			entities: []domain.MessageEntity{entity(domain.EntityQuote, 0, 7)},
+			want:     "<blockquote>one<br>\ntwo</blockquote><br>\nthree",
+		},
+		{
+			name:     "ending together",
+			text:     "ab",
+			entities: []domain.MessageEntity{entity(domain.EntityBold, 0, 2), entity(domain.EntityCode, 1, 1)},
+			want:     "<strong>a<code>b</code></strong>",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := renderHTML(tt.text, tt.entities); got != tt.want {
+			t.Errorf("%s: renderHTML = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFormatMessage(t *testing.T) {
+	tests := []struct {
+		name         string
+		format       string
+		text         string
+		entities     []domain.MessageEntity
+		wantText     string
+		wantEntities []domain.MessageEntity
+		wantErr      error
+	}{
+		{name: "plain by default", text: "**hi**", wantText: "**hi**"},
+		{name: "plain drops entities", format: domain.MessageFormatPlain, text: "hi", entities: []domain.MessageEntity{entity(domain.EntityBold, 0, 2)}, wantText: "hi"},
+		{name: "markdown", format: domain.MessageFormatMarkdown, text: "**hi**", wantText: "hi", wantEntities: []domain.MessageEntity{entity(domain.EntityBold, 0, 2)}},
+		{name: "javascript link stays text", format: domain.MessageFormatMarkdown, text: "[x](javascript:alert(1))", wantText: "[x](javascript:alert(1))"},
+		{name: "unknown format", format: domain.MessageFormatHTML, text: "<b>hi</b>", wantErr: util.ErrInvalidFormatting},
+		{name: "too long", text: strings.Repeat("a", maxMessageLength+1), wantErr: util.ErrMessageTooLong},
+		{
+			name:     "markup does not count towards the length",
+			format:   domain.MessageFormatMarkdown,
+			text:     "**" + strings.Repeat("a", maxMessageLength) + "**",
+			wantText: strings.Repeat("a", maxMessageLength),
+			wantEntities: []domain.MessageEntity{
+				entity(domain.EntityBold, 0, maxMessageLength),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		message := &domain.Message{Text: tt.text, Entities: tt.entities, Format: tt.format}
+		err := formatMessage(message)
+		if err != tt.wantErr {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (message.Text != tt.wantText || !slices.Equal(message.Entities, tt.wantEntities)) {
+			t.Errorf("%s: got %q, %v, want %q, %v", tt.name, message.Text, message.Entities, tt.wantText, tt.wantEntities)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/HellEaglee/Golang-Chat/internal/core/port"
+	"github.com/HellEaglee/Golang-Chat/internal/core/util"
+)
+
+const (
+	// scheduledPollInterval is how often the dispatcher looks for due messages, which bounds how late they go out
+	scheduledPollInterval = 5 * time.Second
+	// scheduledStaleAfter is how long a message can stay claimed before another dispatcher takes it over
+	scheduledStaleAfter = 2 * time.Minute
+	// scheduledBatchSize is how many due messages a dispatcher claims at once
+	scheduledBatchSize = 20
+)
+
+type ScheduledMessageService struct {
+	repo     port.ScheduledMessageRepository
+	messages port.MessageService
+	chatRepo port.ChatRepository
+}
+
+func NewScheduledMessageService(repo port.ScheduledMessageRepository, messages port.MessageService, chatRepo port.ChatRepository) *ScheduledMessageService {
+	return &ScheduledMessageService{
+		repo:     repo,
+		messages: messages,
+		chatRepo: chatRepo,
+	}
+}
+
+// ----------------------------------------------------SCHEDULED MESSAGES----------------------------------------------------
+func (s *ScheduledMessageService) ScheduleMessage(ctx context.Context, scheduled *domain.ScheduledMessage) (*domain.ScheduledMessage, error) {
+	if !scheduled.SendAt.After(time.Now()) {
+		return nil, util.ErrInvalidSendTime
+	}
+	if err := checkScheduledFormat(scheduled); err != nil {
+		return nil, err
+	}
+	if err := s.checkReply(ctx, scheduled); err != nil {
+		return nil, err
+	}
+	scheduled.Status = domain.ScheduledStatusPending
+	return s.repo.CreateScheduledMessage(ctx, scheduled)
+}
+
+func (s *ScheduledMessageService) GetScheduledMessage(ctx context.Context, id string) (*domain.ScheduledMessage, error) {
+	return s.repo.GetScheduledMessageByID(ctx, id)
+}
+
+func (s *ScheduledMessageService) GetScheduledMessages(ctx context.Context, chatID, userID string) ([]domain.ScheduledMessage, error) {
+	return s.repo.GetScheduledMessagesByChatIDUserID(ctx, chatID, userID)
+}
+
+func (s *ScheduledMessageService) UpdateScheduledMessage(ctx context.Context, scheduled *domain.ScheduledMessage) (*domain.ScheduledMessage, error) {
+	if scheduled.Status != domain.ScheduledStatusPending {
+		return nil, util.ErrScheduledMessageNotPending
+	}
+	if !scheduled.SendAt.After(time.Now()) {
+		return nil, util.ErrInvalidSendTime
+	}
+	if err := checkScheduledFormat(scheduled); err != nil {
+		return nil, err
+	}
+	return s.repo.UpdatePendingScheduledMessage(ctx, scheduled)
+}
+
+func (s *ScheduledMessageService) CancelScheduledMessage(ctx context.Context, scheduled *domain.ScheduledMessage) error {
+	if scheduled.Status != domain.ScheduledStatusPending {
+		return util.ErrScheduledMessageNotPending
+	}
+	canceled := *scheduled
+	canceled.Status = domain.ScheduledStatusCanceled
+	_, err := s.repo.UpdatePendingScheduledMessage(ctx, &canceled)
+	return err
+}
+
+// checkScheduledFormat formats the text the way it will be when sent, so that a message that could never be sent is
+// refused now rather than failing in the dispatcher
+func checkScheduledFormat(scheduled *domain.ScheduledMessage) error {
+	if scheduled.Format == "" {
+		scheduled.Format = domain.MessageFormatPlain
+	}
+	return formatMessage(&domain.Message{Text: scheduled.Text, Format: scheduled.Format})
+}
+
+func (s *ScheduledMessageService) checkReply(ctx context.Context, scheduled *domain.ScheduledMessage) error {
+	if scheduled.ReplyToMessageID == nil {
+		return nil
+	}
+	parent, err := s.messages.GetMessage(ctx, scheduled.ReplyToMessageID.String())
+	if err != nil && err != util.ErrDataNotFound {
+		return err
+	}
+	if parent == nil || parent.ChatID != scheduled.ChatID {
+		return util.ErrInvalidReply
+	}
+	return nil
+}
+
+// ----------------------------------------------------DISPATCHER----------------------------------------------------
+// RunDispatcher sends the due messages. Every instance can run it: the messages are claimed in the database,
+// and a message that was sent but not marked as such before a crash is recognised by its id.
+func (s *ScheduledMessageService) RunDispatcher(ctx context.Context) error {
+	ticker := time.NewTicker(scheduledPollInterval)
+	defer ticker.Stop()
+
+	for {
+		for s.dispatchDue(ctx) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatchDue reports whether a full batch was claimed, in which case more messages may be due
+func (s *ScheduledMessageService) dispatchDue(ctx context.Context) bool {
+	claimed, err := s.repo.ClaimDueScheduledMessages(ctx, scheduledBatchSize, scheduledStaleAfter)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("Error claiming scheduled messages", "error", err)
+		}
+		return false
+	}
+
+	for i := range claimed {
+		s.dispatch(ctx, &claimed[i])
+	}
+	return len(claimed) == scheduledBatchSize
+}
+
+func (s *ScheduledMessageService) dispatch(ctx context.Context, scheduled *domain.ScheduledMessage) {
+	id := scheduled.ID.String()
+	status, reason := domain.ScheduledStatusSent, ""
+
+	if err := s.send(ctx, scheduled); err != nil {
+		if !isPermanentSendError(err) {
+			// give it back, the next round or another dispatcher retries it
+			slog.Warn("Error sending scheduled message", "scheduled_message_id", id, "error", err)
+			status = domain.ScheduledStatusPending
+		} else {
+			status, reason = domain.ScheduledStatusFailed, err.Error()
+		}
+	}
+
+	if err := s.repo.UpdateScheduledMessageStatus(ctx, id, status, reason); err != nil {
+		// the claim goes stale and the message is picked up again, which is safe since it keeps its id
+		slog.Error("Error updating scheduled message status", "scheduled_message_id", id, "error", err)
+	}
+}
+
+func (s *ScheduledMessageService) send(ctx context.Context, scheduled *domain.ScheduledMessage) error {
+	sent, err := s.repo.MessageExists(ctx, scheduled.ID.String())
+	if err != nil {
+		return err
+	}
+	if sent {
+		return nil
+	}
+
+	// the sender may have left, or the chat may be gone, since the message was scheduled
+	if _, err := s.chatRepo.GetChatByID(ctx, scheduled.ChatID.String()); err != nil {
+		return err
+	}
+	if _, err := s.chatRepo.GetChatParticipantByChatIDUserID(ctx, scheduled.ChatID.String(), scheduled.UserID.String()); err != nil {
+		if err == util.ErrDataNotFound {
+			return util.ErrForbidden
+		}
+		return err
+	}
+
+	message := &domain.Message{
+		ID:               scheduled.ID,
+		ChatID:           scheduled.ChatID,
+		UserID:           scheduled.UserID,
+		Text:             scheduled.Text,
+		Format:           scheduled.Format,
+		ReplyToMessageID: scheduled.ReplyToMessageID,
+	}
+	_, err = s.messages.CreateMessage(ctx, message)
+	return err
+}
+
+// isPermanentSendError tells the errors that retrying the scheduled message cannot fix.
+// A send racing another one on the message id is not one of them, the retry finds the message and marks it sent.
+func isPermanentSendError(err error) bool {
+	switch err {
+	case util.ErrDataNotFound, util.ErrForbidden, util.ErrInvalidReply, util.ErrInvalidFormatting, util.ErrMessageTooLong:
+		return true
+	}
+	return false
+}
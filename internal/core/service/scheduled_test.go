@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/HellEaglee/Golang-Chat/internal/core/port"
+	"github.com/HellEaglee/Golang-Chat/internal/core/util"
+	"github.com/google/uuid"
+)
+
+type fakeScheduledMessageRepository struct {
+	port.ScheduledMessageRepository
+}
+
+func (r *fakeScheduledMessageRepository) CreateScheduledMessage(ctx context.Context, scheduled *domain.ScheduledMessage) (*domain.ScheduledMessage, error) {
+	return scheduled, nil
+}
+
+func (r *fakeScheduledMessageRepository) MessageExists(ctx context.Context, id string) (bool, error) {
+	return false, nil
+}
+
+// fakeMessageService keeps the messages it is asked to create
+type fakeMessageService struct {
+	port.MessageService
+	created []domain.Message
+}
+
+func (s *fakeMessageService) CreateMessage(ctx context.Context, message *domain.Message) (*domain.Message, error) {
+	s.created = append(s.created, *message)
+	return message, nil
+}
+
+func TestScheduleMessageFormatting(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		text    string
+		wantErr error
+	}{
+		{name: "plain by default", text: "**Happy birthday**"},
+		{name: "markdown", format: domain.MessageFormatMarkdown, text: "**Happy birthday**"},
+		{name: "unknown format", format: domain.MessageFormatHTML, text: "<b>Happy birthday</b>", wantErr: util.ErrInvalidFormatting},
+		{name: "too long", text: strings.Repeat("a", maxMessageLength+1), wantErr: util.ErrMessageTooLong},
+		{name: "markup does not count towards the length", format: domain.MessageFormatMarkdown, text: "**" + strings.Repeat("a", maxMessageLength) + "**"},
+	}
+
+	service := NewScheduledMessageService(&fakeScheduledMessageRepository{}, &fakeMessageService{}, &fakeChatRepository{})
+	for _, tt := range tests {
+		scheduled := &domain.ScheduledMessage{Text: tt.text, Format: tt.format, SendAt: time.Now().Add(time.Hour)}
+		if _, err := service.ScheduleMessage(context.Background(), scheduled); err != tt.wantErr {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		// the text is sent as written, so it is kept that way
+		if scheduled.Text != tt.text {
+			t.Errorf("%s: text became %q", tt.name, scheduled.Text)
+		}
+	}
+}
+
+func TestSendScheduledMessageKeepsFormat(t *testing.T) {
+	chatID, userID := uuid.New(), uuid.New()
+	chatRepo := &fakeChatRepository{participants: []domain.ChatParticipant{{ChatID: chatID, UserID: userID, Role: domain.ChatRoleMember}}}
+	messages := &fakeMessageService{}
+	service := NewScheduledMessageService(&fakeScheduledMessageRepository{}, messages, chatRepo)
+
+	scheduled := &domain.ScheduledMessage{ID: uuid.New(), ChatID: chatID, UserID: userID, Text: "**Happy birthday**", Format: domain.MessageFormatMarkdown}
+	if err := service.send(context.Background(), scheduled); err != nil {
+		t.Fatal(err)
+	}
+	if len(messages.created) != 1 || messages.created[0].Format != domain.MessageFormatMarkdown {
+		t.Fatalf("sent %+v, want one markdown message", messages.created)
+	}
+}
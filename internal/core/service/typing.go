@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/HellEaglee/Golang-Chat/internal/core/port"
+	"github.com/HellEaglee/Golang-Chat/internal/core/util"
+	"github.com/google/uuid"
+)
+
+// typingTimeout is how long a typing indicator lives unless the client refreshes it
+const typingTimeout = 6 * time.Second
+
+type typingKey struct {
+	chatID uuid.UUID
+	userID uuid.UUID
+}
+
+type typingIndicator struct {
+	timer *time.Timer
+}
+
+// TypingService keeps typing indicators in memory only; they expire on their own and are never stored.
+// They go out as signals, which take no event id and are not replayed.
+type TypingService struct {
+	chatRepo  port.ChatRepository
+	publisher port.SignalPublisher
+	// timeout is how long an indicator lives, typingTimeout outside of tests
+	timeout time.Duration
+
+	mu         sync.Mutex
+	indicators map[typingKey]*typingIndicator
+}
+
+func NewTypingService(chatRepo port.ChatRepository, publisher port.SignalPublisher) *TypingService {
+	return &TypingService{
+		chatRepo:   chatRepo,
+		publisher:  publisher,
+		timeout:    typingTimeout,
+		indicators: make(map[typingKey]*typingIndicator),
+	}
+}
+
+// StartTyping announces that the user is typing in the chat, or extends the indicator if it is already shown
+func (s *TypingService) StartTyping(ctx context.Context, chatID, userID string) error {
+	key := typingKey{chatID: uuid.MustParse(chatID), userID: uuid.MustParse(userID)}
+
+	// membership was checked when the indicator was started, so refreshing it skips the database
+	if s.refresh(key) {
+		return nil
+	}
+
+	if err := s.checkParticipant(ctx, chatID, userID); err != nil {
+		return err
+	}
+
+	indicator := &typingIndicator{}
+	s.mu.Lock()
+	if previous, ok := s.indicators[key]; ok {
+		previous.timer.Stop()
+	}
+	indicator.timer = time.AfterFunc(s.timeout, func() { s.expire(key, indicator) })
+	s.indicators[key] = indicator
+	s.mu.Unlock()
+
+	publishSignal(ctx, s.publisher, domain.NewEvent(domain.EventTypingStarted, key.chatID, key.userID))
+	return nil
+}
+
+// StopTyping hides the typing indicator of the user right away
+func (s *TypingService) StopTyping(ctx context.Context, chatID, userID string) error {
+	key := typingKey{chatID: uuid.MustParse(chatID), userID: uuid.MustParse(userID)}
+
+	s.mu.Lock()
+	indicator, ok := s.indicators[key]
+	if ok {
+		indicator.timer.Stop()
+		delete(s.indicators, key)
+	}
+	s.mu.Unlock()
+
+	// the indicator may have been started through another instance
+	if !ok {
+		if err := s.checkParticipant(ctx, chatID, userID); err != nil {
+			return err
+		}
+	}
+
+	publishSignal(ctx, s.publisher, domain.NewEvent(domain.EventTypingStopped, key.chatID, key.userID))
+	return nil
+}
+
+// refresh pushes back the expiry of a live indicator and reports whether there was one
+func (s *TypingService) refresh(key typingKey) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	indicator, ok := s.indicators[key]
+	if !ok {
+		return false
+	}
+	// a timer that already fired is about to expire the indicator, so it has to be started again
+	if !indicator.timer.Stop() {
+		return false
+	}
+	indicator.timer.Reset(s.timeout)
+	return true
+}
+
+func (s *TypingService) expire(key typingKey, indicator *typingIndicator) {
+	s.mu.Lock()
+	if s.indicators[key] != indicator {
+		s.mu.Unlock()
+		return
+	}
+	delete(s.indicators, key)
+	s.mu.Unlock()
+
+	publishSignal(context.Background(), s.publisher, domain.NewEvent(domain.EventTypingStopped, key.chatID, key.userID))
+}
+
+func (s *TypingService) checkParticipant(ctx context.Context, chatID, userID string) error {
+	_, err := s.chatRepo.GetChatParticipantByChatIDUserID(ctx, chatID, userID)
+	if err == util.ErrDataNotFound {
+		return util.ErrForbidden
+	}
+	return err
+}
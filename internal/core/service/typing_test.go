@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
+	"github.com/HellEaglee/Golang-Chat/internal/core/util"
+	"github.com/google/uuid"
+)
+
+// channelPublisher hands the events over a channel, as expired indicators are announced from timer goroutines
+type channelPublisher struct {
+	events chan domain.Event
+}
+
+func (p *channelPublisher) PublishSignal(ctx context.Context, event *domain.Event) error {
+	p.events <- *event
+	return nil
+}
+
+// next returns the type of the next event, or an empty string if none comes within wait
+func (p *channelPublisher) next(wait time.Duration) domain.EventType {
+	// an event already there must win over a timer that fires right away
+	select {
+	case event := <-p.events:
+		return event.Type
+	default:
+	}
+
+	select {
+	case event := <-p.events:
+		return event.Type
+	case <-time.After(wait):
+		return ""
+	}
+}
+
+func newTypingFixture(t *testing.T) (*TypingService, *channelPublisher, string, string) {
+	t.Helper()
+	chatID, userID := uuid.New(), uuid.New()
+	repo := &fakeChatRepository{participants: []domain.ChatParticipant{{ChatID: chatID, UserID: userID, Role: domain.ChatRoleMember}}}
+	publisher := &channelPublisher{events: make(chan domain.Event, 10)}
+	service := NewTypingService(repo, publisher)
+	service.timeout = 200 * time.Millisecond
+	return service, publisher, chatID.String(), userID.String()
+}
+
+func TestTypingExpires(t *testing.T) {
+	service, publisher, chatID, userID := newTypingFixture(t)
+
+	if err := service.StartTyping(context.Background(), chatID, userID); err != nil {
+		t.Fatal(err)
+	}
+	if got := publisher.next(0); got != domain.EventTypingStarted {
+		t.Fatalf("got %q, want %s", got, domain.EventTypingStarted)
+	}
+	if got := publisher.next(10 * service.timeout); got != domain.EventTypingStopped {
+		t.Fatalf("got %q, want %s once the indicator expires", got, domain.EventTypingStopped)
+	}
+	if got := publisher.next(2 * service.timeout); got != "" {
+		t.Fatalf("got %q after the indicator expired", got)
+	}
+}
+
+func TestTypingRefreshDelaysExpiry(t *testing.T) {
+	service, publisher, chatID, userID := newTypingFixture(t)
+	ctx := context.Background()
+
+	service.StartTyping(ctx, chatID, userID)
+	publisher.next(0)
+	// keep typing for longer than a timeout; refreshing is silent
+	for i := 0; i < 6; i++ {
+		time.Sleep(service.timeout / 4)
+		if err := service.StartTyping(ctx, chatID, userID); err != nil {
+			t.Fatal(err)
+		}
+		if got := publisher.next(0); got != "" {
+			t.Fatalf("refresh %d published %q", i, got)
+		}
+	}
+
+	if got := publisher.next(10 * service.timeout); got != domain.EventTypingStopped {
+		t.Fatalf("got %q, want %s once refreshing stops", got, domain.EventTypingStopped)
+	}
+}
+
+func TestTypingStopCancelsExpiry(t *testing.T) {
+	service, publisher, chatID, userID := newTypingFixture(t)
+	ctx := context.Background()
+
+	service.StartTyping(ctx, chatID, userID)
+	publisher.next(0)
+	if err := service.StopTyping(ctx, chatID, userID); err != nil {
+		t.Fatal(err)
+	}
+	if got := publisher.next(0); got != domain.EventTypingStopped {
+		t.Fatalf("got %q, want %s", got, domain.EventTypingStopped)
+	}
+	if got := publisher.next(3 * service.timeout); got != "" {
+		t.Fatalf("got %q after the indicator was stopped", got)
+	}
+}
+
+func TestTypingRequiresParticipant(t *testing.T) {
+	service, publisher, chatID, _ := newTypingFixture(t)
+	outsider := uuid.NewString()
+
+	if err := service.StartTyping(context.Background(), chatID, outsider); err != util.ErrForbidden {
+		t.Errorf("StartTyping error = %v, want %v", err, util.ErrForbidden)
+	}
+	if err := service.StopTyping(context.Background(), chatID, outsider); err != util.ErrForbidden {
+		t.Errorf("StopTyping error = %v, want %v", err, util.ErrForbidden)
+	}
+	if got := publisher.next(0); got != "" {
+		t.Errorf("published %q for someone outside the chat", got)
+	}
+}
@@ -6,14 +6,33 @@ import (
 	"github.com/HellEaglee/Golang-Chat/internal/core/domain"
 	"github.com/HellEaglee/Golang-Chat/internal/core/port"
 	"github.com/HellEaglee/Golang-Chat/internal/core/util"
+	"github.com/google/uuid"
 )
 
 type UserService struct {
-	repo port.UserRepository
+	repo     port.UserRepository
+	presence port.PresenceService
 }
 
-func NewUserService(repo port.UserRepository) *UserService {
-	return &UserService{repo: repo}
+func NewUserService(repo port.UserRepository, presence port.PresenceService) *UserService {
+	return &UserService{repo: repo, presence: presence}
+}
+
+// withStatus fills in the live presence status, which is not stored with the users
+func (s *UserService) withStatus(ctx context.Context, users ...*domain.User) error {
+	userIDs := make([]uuid.UUID, len(users))
+	for i, user := range users {
+		userIDs[i] = user.ID
+	}
+
+	statuses, err := s.presence.GetStatuses(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		user.Status = statuses[user.ID]
+	}
+	return nil
 }
 
 func (s *UserService) CreateUser(ctx context.Context, user *domain.User) (*domain.User, error) {
@@ -25,15 +44,41 @@ func (s *UserService) CreateUser(ctx context.Context, user *domain.User) (*domai
 
 	user.Name = name
 	user.Password = hashedPassword
-	return s.repo.CreateUser(ctx, user)
+	created, err := s.repo.CreateUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.withStatus(ctx, created); err != nil {
+		return nil, err
+	}
+	return created, nil
 }
 
 func (s *UserService) GetUser(ctx context.Context, id string) (*domain.User, error) {
-	return s.repo.GetUserByID(ctx, id)
+	user, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.withStatus(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
 func (s *UserService) GetUsers(ctx context.Context, skip uint64, limit uint64) ([]domain.User, error) {
-	return s.repo.GetUsers(ctx, skip, limit)
+	users, err := s.repo.GetUsers(ctx, skip, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	pointers := make([]*domain.User, len(users))
+	for i := range users {
+		pointers[i] = &users[i]
+	}
+	if err := s.withStatus(ctx, pointers...); err != nil {
+		return nil, err
+	}
+	return users, nil
 }
 
 func (s *UserService) UpdateUser(ctx context.Context, user *domain.User) (*domain.User, error) {
@@ -42,7 +87,14 @@ func (s *UserService) UpdateUser(ctx context.Context, user *domain.User) (*domai
 		return nil, util.ErrInternal
 	}
 	user.Password = hashedPassword
-	return s.repo.UpdateUser(ctx, user)
+	updated, err := s.repo.UpdateUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.withStatus(ctx, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
 }
 
 func (s *UserService) DeleteUser(ctx context.Context, id string) error {
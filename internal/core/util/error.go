@@ -25,4 +25,20 @@ var (
 	ErrUnauthorized               = errors.New("user is unauthorized to access the resource")
 	ErrForbidden                  = errors.New("user is forbidden to access the resource")
 	ErrSessionRevoked             = errors.New("session has been revoked")
+	ErrInvalidCursor              = errors.New("pagination cursor is invalid")
+	ErrLastChatAdmin              = errors.New("the last admin of the chat must hand over the role first")
+	ErrInvalidDirectChat          = errors.New("a direct chat needs exactly two different users")
+	ErrInvalidReaction            = errors.New("reaction must be a single emoji")
+	ErrEditWindowExpired          = errors.New("the message can no longer be edited")
+	ErrInvalidReply               = errors.New("a reply must target a message in the same chat")
+	ErrPinLimitReached            = errors.New("the chat already has the maximum number of pinned messages")
+	ErrInvalidAttachment          = errors.New("attachments must be unsent uploads of the sender to the same chat")
+	ErrFileTooLarge               = errors.New("the file is larger than allowed")
+	ErrUnsupportedFileType        = errors.New("the file type is not allowed")
+	ErrScheduledMessageNotPending = errors.New("the scheduled message was already sent or canceled")
+	ErrInvalidSendTime            = errors.New("the send time must be in the future")
+	ErrInvalidMessageTTL          = errors.New("the message TTL must be zero or between a minute and a year")
+	ErrInvalidForward             = errors.New("only messages sent by users in the chat can be forwarded")
+	ErrInvalidFormatting          = errors.New("the message formatting is invalid")
+	ErrMessageTooLong             = errors.New("the message text is longer than allowed")
 )